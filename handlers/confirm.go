@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/confirm"
+)
+
+// confirmDestructive implements the two-phase confirmation of destructive tools.
+// Without a confirmation token it issues one and returns a result carrying the
+// token and the impact summary, which the handler must return as is. With a
+// token it redeems it and returns nil so the handler proceeds. Confirmation is
+// skipped when confirmations is nil.
+//
+// The token goes back to the model, which can replay it at once, so this is a
+// soft safeguard that surfaces the impact rather than a human approval gate.
+func confirmDestructive(ctx context.Context, confirmations *confirm.Store, tool string, args map[string]interface{}, impact func() (interface{}, error)) (*mcp.CallToolResult, error) {
+	if confirmations == nil {
+		return nil, nil
+	}
+
+	if _, hasToken := args[confirm.TokenArgument]; hasToken {
+		if err := confirmations.Redeem(ctx, tool, args); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	summary, err := impact()
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize the impact: %w", err)
+	}
+
+	token, expires, err := confirmations.Issue(ctx, tool, args)
+	if err != nil {
+		return nil, err
+	}
+
+	response := map[string]interface{}{
+		"confirmationRequired": true,
+		"confirmationToken":    token,
+		"expiresAt":            expires.UTC().Format(time.RFC3339),
+		"impact":               summary,
+		"message": fmt.Sprintf("Nothing was changed. This operation is destructive: show the impact to the user and, once they approve, "+
+			"call %s again with exactly the same arguments plus %s=%q before it expires.", tool, confirm.TokenArgument, token),
+	}
+
+	jsonResponse, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize response: %w", err)
+	}
+
+	return mcp.NewToolResultText(string(jsonResponse)), nil
+}
@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/cluster"
+)
+
+// ListContexts returns a handler function for the listContexts tool.
+// It lists the contexts defined in the kubeconfig. The result is serialized
+// to JSON and returned.
+func ListContexts(registry *cluster.Registry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		contexts, err := registry.Contexts()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list contexts: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(contexts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// GetCurrentContext returns a handler function for the getCurrentContext tool.
+// It returns the kubeconfig's current context. The result is serialized
+// to JSON and returned.
+func GetCurrentContext(registry *cluster.Registry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		current, err := registry.CurrentContext()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current context: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(current)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
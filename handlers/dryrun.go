@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// dryRunResult returns the result of a dry-run operation, marked so that it
+// cannot be mistaken for a change that was actually applied.
+func dryRunResult(message string, result interface{}) (*mcp.CallToolResult, error) {
+	response := map[string]interface{}{
+		"dryRun":  true,
+		"message": message,
+		"result":  result,
+	}
+
+	jsonResponse, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize response: %w", err)
+	}
+
+	return mcp.NewToolResultText(string(jsonResponse)), nil
+}
+
+// noDryRunTools are the mutating tools that cannot validate a change without
+// making it, such as helmRepoAdd, which writes the repository file and
+// downloads the index. ForceDryRun refuses them.
+var noDryRunTools = map[string]bool{
+	"helmRepoAdd": true,
+}
+
+// ForceDryRun returns a tool handler middleware that sets the dryRun argument
+// on every call, so mutating tools only ever validate their changes, and
+// refuses the mutating tools that have no dry run.
+// It is used for the server-wide --dry-run mode.
+func ForceDryRun() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if noDryRunTools[request.Params.Name] {
+				return mcp.NewToolResultError(fmt.Sprintf("%s cannot be dry-run and is disabled while the server runs in dry-run mode", request.Params.Name)), nil
+			}
+
+			args, _ := request.Params.Arguments.(map[string]interface{})
+
+			forced := make(map[string]interface{}, len(args)+1)
+			for key, value := range args {
+				forced[key] = value
+			}
+			forced["dryRun"] = true
+			request.Params.Arguments = forced
+
+			return next(ctx, request)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestForceDryRun(t *testing.T) {
+	tests := []struct {
+		tool    string
+		refused bool
+	}{
+		{tool: "applyResource"},
+		{tool: "helmInstall"},
+		{tool: "listResources"},
+		{tool: "helmRepoAdd", refused: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			var called bool
+			handler := ForceDryRun()(func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				called = true
+				if dryRun := getBoolArg(request.GetArguments(), "dryRun", false); !dryRun {
+					t.Errorf("%s was called without dryRun", tt.tool)
+				}
+				return mcp.NewToolResultText("ok"), nil
+			})
+
+			request := mcp.CallToolRequest{}
+			request.Params.Name = tt.tool
+			request.Params.Arguments = map[string]interface{}{"dryRun": false}
+			result, err := handler(context.Background(), request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if called == tt.refused || result.IsError != tt.refused {
+				t.Errorf("called = %v, error result = %v; want the call refused = %v", called, result.IsError, tt.refused)
+			}
+		})
+	}
+}
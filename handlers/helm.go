@@ -4,21 +4,46 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"strconv"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/cluster"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/confirm"
 	"github.com/reza-gholizade/k8s-mcp-server/pkg/helm"
 )
 
+// helmClientFor returns the Helm client for the kubeconfig context named
+// by the optional "context" argument, falling back to the current context.
+// When impersonation is enabled, the client acts as the caller found in ctx.
+func helmClientFor(ctx context.Context, registry *cluster.Registry, args map[string]interface{}) (*helm.Client, error) {
+	return registry.Helm(ctx, getStringArg(args, "context", ""))
+}
+
+// invalidateDiscovery drops the cached API discovery of the context named by
+// the "context" argument after a release changed, since charts can install or
+// remove CustomResourceDefinitions. Failures are logged to stderr, since
+// stdout carries the JSON-RPC stream in stdio mode.
+func invalidateDiscovery(registry *cluster.Registry, args map[string]interface{}) {
+	if err := registry.InvalidateDiscovery(getStringArg(args, "context", "")); err != nil {
+		log.Printf("Failed to invalidate API discovery cache: %v", err)
+	}
+}
+
 // HelmInstall returns a handler function for the helmInstall tool
 
-func HelmInstall(client *helm.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func HelmInstall(registry *cluster.Registry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]interface{})
 		if !ok {
 			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
 		}
 
+		client, err := helmClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
 		releaseName, err := getRequiredStringArg(args, "releaseName")
 		if err != nil {
 			return nil, err
@@ -39,11 +64,19 @@ func HelmInstall(client *helm.Client) func(ctx context.Context, request mcp.Call
 			}
 		}
 
-		release, err := client.InstallChart(ctx, namespace, releaseName, chartName, repoURL, values)
+		dryRun := getBoolArg(args, "dryRun", false)
+
+		release, err := client.InstallChart(ctx, namespace, releaseName, chartName, repoURL, values, dryRun)
 		if err != nil {
 			return nil, fmt.Errorf("failed to install chart: %w", err)
 		}
 
+		if dryRun {
+			return dryRunResult("The chart was rendered and validated against the cluster but not installed; result is the release that would be installed, including its manifest", release)
+		}
+
+		invalidateDiscovery(registry, args)
+
 		jsonResponse, err := json.Marshal(release)
 		if err != nil {
 			return nil, fmt.Errorf("failed to serialize response: %w", err)
@@ -54,13 +87,18 @@ func HelmInstall(client *helm.Client) func(ctx context.Context, request mcp.Call
 }
 
 // HelmUpgrade returns a handler function for the helmUpgrade tool
-func HelmUpgrade(client *helm.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func HelmUpgrade(registry *cluster.Registry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]interface{})
 		if !ok {
 			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
 		}
 
+		client, err := helmClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
 		releaseName, err := getRequiredStringArg(args, "releaseName")
 		if err != nil {
 			return nil, err
@@ -80,11 +118,19 @@ func HelmUpgrade(client *helm.Client) func(ctx context.Context, request mcp.Call
 			}
 		}
 
-		release, err := client.UpgradeChart(ctx, namespace, releaseName, chartName, values)
+		dryRun := getBoolArg(args, "dryRun", false)
+
+		release, err := client.UpgradeChart(ctx, namespace, releaseName, chartName, values, dryRun)
 		if err != nil {
 			return nil, fmt.Errorf("failed to upgrade chart: %w", err)
 		}
 
+		if dryRun {
+			return dryRunResult("The upgrade was rendered and validated against the cluster but not applied; result is the release as it would be upgraded, including its manifest", release)
+		}
+
+		invalidateDiscovery(registry, args)
+
 		jsonResponse, err := json.Marshal(release)
 		if err != nil {
 			return nil, fmt.Errorf("failed to serialize response: %w", err)
@@ -94,14 +140,21 @@ func HelmUpgrade(client *helm.Client) func(ctx context.Context, request mcp.Call
 	}
 }
 
-// HelmUninstall returns a handler function for the helmUninstall tool
-func HelmUninstall(client *helm.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+// HelmUninstall returns a handler function for the helmUninstall tool.
+// When confirmations is set, the release is only uninstalled on a second call
+// carrying the confirmation token issued with the list of affected resources.
+func HelmUninstall(registry *cluster.Registry, confirmations *confirm.Store) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]interface{})
 		if !ok {
 			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
 		}
 
+		client, err := helmClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
 		releaseName, err := getRequiredStringArg(args, "releaseName")
 		if err != nil {
 			return nil, err
@@ -109,11 +162,28 @@ func HelmUninstall(client *helm.Client) func(ctx context.Context, request mcp.Ca
 
 		namespace := getStringArg(args, "namespace", "default")
 
-		err = client.UninstallChart(ctx, namespace, releaseName)
+		dryRun := getBoolArg(args, "dryRun", false)
+
+		if !dryRun {
+			result, err := confirmDestructive(ctx, confirmations, request.Params.Name, args, func() (interface{}, error) {
+				return client.UninstallImpact(ctx, namespace, releaseName)
+			})
+			if result != nil || err != nil {
+				return result, err
+			}
+		}
+
+		release, err := client.UninstallChart(ctx, namespace, releaseName, dryRun)
 		if err != nil {
 			return nil, fmt.Errorf("failed to uninstall chart: %w", err)
 		}
 
+		if dryRun {
+			return dryRunResult(fmt.Sprintf("Release '%s' in namespace '%s' was not uninstalled; result is the release whose resources would be removed", releaseName, namespace), release)
+		}
+
+		invalidateDiscovery(registry, args)
+
 		response := map[string]string{
 			"status":  "success",
 			"message": fmt.Sprintf("Successfully uninstalled release '%s' from namespace '%s'", releaseName, namespace),
@@ -129,13 +199,18 @@ func HelmUninstall(client *helm.Client) func(ctx context.Context, request mcp.Ca
 }
 
 // HelmList returns a handler function for the helmList tool
-func HelmList(client *helm.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func HelmList(registry *cluster.Registry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]interface{})
 		if !ok {
 			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
 		}
 
+		client, err := helmClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
 		namespace := getStringArg(args, "namespace", "")
 
 		releases, err := client.ListReleases(ctx, namespace)
@@ -153,13 +228,18 @@ func HelmList(client *helm.Client) func(ctx context.Context, request mcp.CallToo
 }
 
 // HelmGet returns a handler function for the helmGet tool
-func HelmGet(client *helm.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func HelmGet(registry *cluster.Registry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]interface{})
 		if !ok {
 			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
 		}
 
+		client, err := helmClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
 		releaseName, err := getRequiredStringArg(args, "releaseName")
 		if err != nil {
 			return nil, err
@@ -182,13 +262,18 @@ func HelmGet(client *helm.Client) func(ctx context.Context, request mcp.CallTool
 }
 
 // HelmHistory returns a handler function for the helmHistory tool
-func HelmHistory(client *helm.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func HelmHistory(registry *cluster.Registry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]interface{})
 		if !ok {
 			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
 		}
 
+		client, err := helmClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
 		releaseName, err := getRequiredStringArg(args, "releaseName")
 		if err != nil {
 			return nil, err
@@ -211,13 +296,18 @@ func HelmHistory(client *helm.Client) func(ctx context.Context, request mcp.Call
 }
 
 // HelmRollback returns a handler function for the helmRollback tool
-func HelmRollback(client *helm.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func HelmRollback(registry *cluster.Registry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]interface{})
 		if !ok {
 			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
 		}
 
+		client, err := helmClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
 		releaseName, err := getRequiredStringArg(args, "releaseName")
 		if err != nil {
 			return nil, err
@@ -232,11 +322,19 @@ func HelmRollback(client *helm.Client) func(ctx context.Context, request mcp.Cal
 			}
 		}
 
-		err = client.RollbackRelease(ctx, namespace, releaseName, revision)
+		dryRun := getBoolArg(args, "dryRun", false)
+
+		target, err := client.RollbackRelease(ctx, namespace, releaseName, revision, dryRun)
 		if err != nil {
 			return nil, fmt.Errorf("failed to rollback release: %w", err)
 		}
 
+		if dryRun {
+			return dryRunResult(fmt.Sprintf("Release '%s' in namespace '%s' was not rolled back; result is the revision that would be restored", releaseName, namespace), target)
+		}
+
+		invalidateDiscovery(registry, args)
+
 		response := map[string]interface{}{
 			"status":   "success",
 			"message":  fmt.Sprintf("Successfully rolled back release '%s' in namespace '%s'", releaseName, namespace),
@@ -253,13 +351,18 @@ func HelmRollback(client *helm.Client) func(ctx context.Context, request mcp.Cal
 }
 
 // HelmRepoAdd returns a handler function for the helmRepoAdd tool
-func HelmRepoAdd(client *helm.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func HelmRepoAdd(registry *cluster.Registry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]interface{})
 		if !ok {
 			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
 		}
 
+		client, err := helmClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
 		repoName, err := getRequiredStringArg(args, "repoName")
 		if err != nil {
 			return nil, err
@@ -289,8 +392,16 @@ func HelmRepoAdd(client *helm.Client) func(ctx context.Context, request mcp.Call
 	}
 }
 
-func HelmRepoList(client *helm.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func HelmRepoList(registry *cluster.Registry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		// This tool may be called without any arguments
+		args, _ := request.Params.Arguments.(map[string]interface{})
+
+		client, err := helmClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
 		repos, err := client.HelmRepoList(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("failed to list repositories: %w", err)
@@ -4,11 +4,20 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/cluster"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/confirm"
 	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
 )
 
 // Helper functions for consistent parameter extraction
@@ -26,6 +35,31 @@ func getBoolArg(args map[string]interface{}, key string, defaultValue bool) bool
 	return defaultValue
 }
 
+func getIntArg(args map[string]interface{}, key string, defaultValue int64) int64 {
+	switch val := args[key].(type) {
+	case float64:
+		return int64(val)
+	case int:
+		return int64(val)
+	case int64:
+		return val
+	case string:
+		if parsed, err := strconv.ParseInt(val, 10, 64); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getOptionalIntArg returns an integer argument, or nil if it is not set.
+func getOptionalIntArg(args map[string]interface{}, key string) *int64 {
+	if value, ok := args[key]; !ok || value == nil {
+		return nil
+	}
+	value := getIntArg(args, key, 0)
+	return &value
+}
+
 func getRequiredStringArg(args map[string]interface{}, key string) (string, error) {
 	val, ok := args[key].(string)
 	if !ok || val == "" {
@@ -34,11 +68,30 @@ func getRequiredStringArg(args map[string]interface{}, key string) (string, erro
 	return val, nil
 }
 
+// getListArg returns a comma-separated string argument as a list, skipping
+// empty entries.
+func getListArg(args map[string]interface{}, key string) []string {
+	var values []string
+	for _, value := range strings.Split(getStringArg(args, key, ""), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// k8sClientFor returns the Kubernetes client for the kubeconfig context named
+// by the optional "context" argument, falling back to the current context.
+// When impersonation is enabled, the client acts as the caller found in ctx.
+func k8sClientFor(ctx context.Context, registry *cluster.Registry, args map[string]interface{}) (*k8s.Client, error) {
+	return registry.K8s(ctx, getStringArg(args, "context", ""))
+}
+
 // GetAPIResources returns a handler function for the getAPIResources tool.
 // It retrieves API resources from the Kubernetes cluster based on the provided
 // context and parameters (includeNamespaceScoped, includeClusterScoped).
 // The result is serialized to JSON and returned.
-func GetAPIResources(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func GetAPIResources(registry *cluster.Registry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments
 		args, ok := request.Params.Arguments.(map[string]interface{})
@@ -46,6 +99,11 @@ func GetAPIResources(client *k8s.Client) func(ctx context.Context, request mcp.C
 			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
 		}
 
+		client, err := k8sClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
 		includeNamespaceScoped := getBoolArg(args, "includeNamespaceScoped", true)
 		includeClusterScoped := getBoolArg(args, "includeClusterScoped", true)
 
@@ -68,8 +126,10 @@ func GetAPIResources(client *k8s.Client) func(ctx context.Context, request mcp.C
 
 // ListResources returns a handler function for the listResources tool.
 // It lists resources in the Kubernetes cluster based on the provided kind,
-// namespace, and labelSelector. The result is serialized to JSON and returned.
-func ListResources(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+// namespace, and labelSelector. Results are paginated with the limit and
+// continue arguments; pages hold at most maxItems items (unbounded if 0).
+// The result is serialized to JSON and returned.
+func ListResources(registry *cluster.Registry, maxItems int64) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		// Extract arguments - using capital K to match your tools definition
 		args, ok := request.Params.Arguments.(map[string]interface{})
@@ -77,6 +137,11 @@ func ListResources(client *k8s.Client) func(ctx context.Context, request mcp.Cal
 			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
 		}
 
+		client, err := k8sClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
 		kind, err := getRequiredStringArg(args, "Kind")
 		if err != nil {
 			return nil, err
@@ -85,9 +150,43 @@ func ListResources(client *k8s.Client) func(ctx context.Context, request mcp.Cal
 		namespace := getStringArg(args, "namespace", "")
 		labelSelector := getStringArg(args, "labelSelector", "")
 		fieldSelector := getStringArg(args, "fieldSelector", "")
+		continueToken := getStringArg(args, "continue", "")
+
+		// Bound the page size so results fit in the model's context
+		limit := getIntArg(args, "limit", 0)
+		if limit < 0 {
+			return nil, fmt.Errorf("limit must not be negative")
+		}
+		if maxItems > 0 && (limit == 0 || limit > maxItems) {
+			limit = maxItems
+		}
+
+		var columns []k8s.Column
+		if fields := getStringArg(args, "fields", ""); fields != "" {
+			if columns, err = k8s.ParseColumns(fields); err != nil {
+				return nil, err
+			}
+		}
+
+		output, err := getOutputArg(args, "json", "table")
+		if err != nil {
+			return nil, err
+		}
 
 		// Fetch resources
-		resources, err := client.ListResources(ctx, kind, namespace, labelSelector, fieldSelector)
+		options := k8s.ListOptions{
+			LabelSelector: labelSelector,
+			FieldSelector: fieldSelector,
+			Limit:         limit,
+			Continue:      continueToken,
+			Columns:       columns,
+		}
+		var resources interface{}
+		if output == "table" {
+			resources, err = client.ListResourcesTable(ctx, kind, namespace, options)
+		} else {
+			resources, err = client.ListResources(ctx, kind, namespace, options)
+		}
 		if err != nil {
 			return nil, fmt.Errorf("failed to list resources for kind '%s': %w", kind, err)
 		}
@@ -106,13 +205,18 @@ func ListResources(client *k8s.Client) func(ctx context.Context, request mcp.Cal
 // GetResources returns a handler function for the getResource tool.
 // It retrieves a specific resource from the Kubernetes cluster based on the
 // provided kind, name, and namespace. The result is serialized to JSON and returned.
-func GetResources(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func GetResources(registry *cluster.Registry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]interface{})
 		if !ok {
 			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
 		}
 
+		client, err := k8sClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
 		kind, err := getRequiredStringArg(args, "kind")
 		if err != nil {
 			return nil, err
@@ -125,11 +229,32 @@ func GetResources(client *k8s.Client) func(ctx context.Context, request mcp.Call
 
 		namespace := getStringArg(args, "namespace", "")
 
-		resource, err := client.GetResource(ctx, kind, name, namespace)
+		output, err := getOutputArg(args, "json", "yaml", "table")
+		if err != nil {
+			return nil, err
+		}
+
+		var resource interface{}
+		if output == "table" {
+			resource, err = client.GetResourceTable(ctx, kind, name, namespace)
+		} else {
+			resource, err = client.GetResource(ctx, kind, name, namespace, k8s.GetOptions{
+				Strip:    getBoolArg(args, "strip", true),
+				Sections: getListArg(args, "sections"),
+			})
+		}
 		if err != nil {
 			return nil, fmt.Errorf("failed to get resource '%s' of kind '%s': %w", name, kind, err)
 		}
 
+		if output == "yaml" {
+			yamlResponse, err := yaml.Marshal(resource)
+			if err != nil {
+				return nil, fmt.Errorf("failed to serialize response: %w", err)
+			}
+			return mcp.NewToolResultText(string(yamlResponse)), nil
+		}
+
 		jsonResponse, err := json.Marshal(resource)
 		if err != nil {
 			return nil, fmt.Errorf("failed to serialize response: %w", err)
@@ -139,17 +264,72 @@ func GetResources(client *k8s.Client) func(ctx context.Context, request mcp.Call
 	}
 }
 
+// getOutputArg returns the output argument, which must be one of formats.
+// The first format is the default.
+func getOutputArg(args map[string]interface{}, formats ...string) (string, error) {
+	output := getStringArg(args, "output", "")
+	if output == "" {
+		return formats[0], nil
+	}
+	for _, format := range formats {
+		if output == format {
+			return output, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported output %q: must be one of %s", output, strings.Join(formats, ", "))
+}
+
+// RefreshDiscovery returns a handler function for the refreshDiscovery tool.
+// It drops the cached API discovery of a context, so resource types of newly
+// installed or removed CRDs are seen, and returns a summary of the API
+// resources found by fetching it again, serialized to JSON.
+func RefreshDiscovery(registry *cluster.Registry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		client, err := k8sClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
+		// Clients of other callers of the same context share the stale view
+		if err := registry.InvalidateDiscovery(getStringArg(args, "context", "")); err != nil {
+			return nil, err
+		}
+
+		summary, err := client.RefreshDiscovery()
+		if err != nil {
+			return nil, fmt.Errorf("failed to refresh API discovery: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(summary)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
 // DescribeResources returns a handler function for the describeResource tool.
-// It fetches the description (manifest) of a specific resource from the
-// Kubernetes cluster based on the provided kind, name, and namespace.
-// The result is serialized to JSON and returned.
-func DescribeResources(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+// It returns the kubectl describe output of a specific resource from the
+// Kubernetes cluster based on the provided kind, name, and namespace, including
+// its events and related objects, as text.
+func DescribeResources(registry *cluster.Registry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]interface{})
 		if !ok {
 			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
 		}
 
+		client, err := k8sClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
 		// Extract arguments - using capital K to match your tools definition
 		kind, err := getRequiredStringArg(args, "Kind")
 		if err != nil {
@@ -169,27 +349,26 @@ func DescribeResources(client *k8s.Client) func(ctx context.Context, request mcp
 			return nil, fmt.Errorf("failed to describe resource '%s' of kind '%s': %w", name, kind, err)
 		}
 
-		// Serialize response to JSON
-		jsonResponse, err := json.Marshal(resourceDescription)
-		if err != nil {
-			return nil, fmt.Errorf("failed to serialize response: %w", err)
-		}
-
-		// Return JSON response using NewToolResultText
-		return mcp.NewToolResultText(string(jsonResponse)), nil
+		// The description is already human-readable text, like kubectl describe
+		return mcp.NewToolResultText(resourceDescription), nil
 	}
 }
 
 // GetPodsLogs returns a handler function for the getPodsLogs tool.
 // It retrieves logs for a specific pod from the Kubernetes cluster based on the
 // provided name and namespace. The result is serialized to JSON and returned.
-func GetPodsLogs(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func GetPodsLogs(registry *cluster.Registry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]interface{})
 		if !ok {
 			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
 		}
 
+		client, err := k8sClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
 		// Using capital N to match your tools definition
 		name, err := getRequiredStringArg(args, "Name")
 		if err != nil {
@@ -201,9 +380,12 @@ func GetPodsLogs(client *k8s.Client) func(ctx context.Context, request mcp.CallT
 			return nil, err
 		}
 
-		containerName := getStringArg(args, "containerName", "")
+		options, err := logOptions(args)
+		if err != nil {
+			return nil, err
+		}
 
-		logs, err := client.GetPodsLogs(ctx, namespace, containerName, name)
+		logs, err := client.GetPodsLogs(ctx, namespace, name, options)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get logs for pod '%s': %w", name, err)
 		}
@@ -213,17 +395,191 @@ func GetPodsLogs(client *k8s.Client) func(ctx context.Context, request mcp.CallT
 	}
 }
 
+// GetWorkloadLogs returns a handler function for the getWorkloadLogs tool.
+// It retrieves the logs of all pods of a workload, or of the pods matching a
+// label selector, interleaved by time with a [pod/container] prefix per line.
+func GetWorkloadLogs(registry *cluster.Registry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		client, err := k8sClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
+		namespace, err := getRequiredStringArg(args, "namespace")
+		if err != nil {
+			return nil, err
+		}
+
+		kind := getStringArg(args, "kind", "")
+		name := getStringArg(args, "name", "")
+		labelSelector := getStringArg(args, "labelSelector", "")
+
+		options, err := logOptions(args)
+		if err != nil {
+			return nil, err
+		}
+
+		logs, err := client.GetWorkloadLogs(ctx, namespace, kind, name, labelSelector, options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get workload logs: %w", err)
+		}
+
+		// Return logs as plain text instead of JSON for better readability
+		return mcp.NewToolResultText(logs.String()), nil
+	}
+}
+
+// FollowPodLogs returns a handler function for the followPodLogs tool.
+// It follows the logs of a container for a bounded duration or until a
+// pattern appears, pushing every line to the client as a progress or log
+// notification, and returns a summary with the last lines once it stops.
+func FollowPodLogs(registry *cluster.Registry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		client, err := k8sClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
+		name, err := getRequiredStringArg(args, "Name")
+		if err != nil {
+			return nil, err
+		}
+
+		namespace, err := getRequiredStringArg(args, "namespace")
+		if err != nil {
+			return nil, err
+		}
+
+		selection, err := logOptions(args)
+		if err != nil {
+			return nil, err
+		}
+		options := k8s.FollowOptions{
+			LogOptions: selection,
+			Duration:   time.Duration(getIntArg(args, "durationSeconds", 0)) * time.Second,
+			MaxLines:   int(getIntArg(args, "maxLines", 0)),
+		}
+		if until := getStringArg(args, "until", ""); until != "" {
+			if options.Until, err = regexp.Compile(until); err != nil {
+				return nil, fmt.Errorf("invalid until pattern: %w", err)
+			}
+		}
+
+		notifier := newLogNotifier(ctx, request, name)
+		result, err := client.FollowPodLogs(ctx, namespace, name, options, func(line string) {
+			notifier.notify(ctx, line)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to follow logs for pod '%s': %w", name, err)
+		}
+
+		summary := result.String()
+		if notifier.dropped > 0 {
+			summary += fmt.Sprintf("--- %d of %d lines could not be sent as notifications ---\n", notifier.dropped, notifier.lines)
+		}
+		return mcp.NewToolResultText(summary), nil
+	}
+}
+
+// SummarizeLogs returns a handler function for the summarizeLogs tool.
+// It clusters the lines of a pod's logs into templates and returns the most
+// frequent ones with their counts, first and last occurrence and an example.
+func SummarizeLogs(registry *cluster.Registry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		client, err := k8sClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
+		name, err := getRequiredStringArg(args, "Name")
+		if err != nil {
+			return nil, err
+		}
+
+		namespace, err := getRequiredStringArg(args, "namespace")
+		if err != nil {
+			return nil, err
+		}
+
+		options, err := logOptions(args)
+		if err != nil {
+			return nil, err
+		}
+
+		summary, err := client.SummarizePodLogs(ctx, namespace, name, options, int(getIntArg(args, "maxTemplates", 0)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to summarize logs for pod '%s': %w", name, err)
+		}
+
+		return mcp.NewToolResultText(summary.String()), nil
+	}
+}
+
+// logOptions extracts the log selection arguments shared by the log tools.
+func logOptions(args map[string]interface{}) (k8s.LogOptions, error) {
+	options := k8s.LogOptions{
+		Container:           getStringArg(args, "containerName", ""),
+		InitContainers:      getBoolArg(args, "initContainers", false),
+		EphemeralContainers: getBoolArg(args, "ephemeralContainers", false),
+		TailLines:           getIntArg(args, "tailLines", 0),
+		SinceSeconds:        getOptionalIntArg(args, "sinceSeconds"),
+		Previous:            getBoolArg(args, "previous", false),
+		Timestamps:          getBoolArg(args, "timestamps", false),
+		LimitBytes:          getOptionalIntArg(args, "limitBytes"),
+	}
+
+	filter, err := k8s.NewLogFilter(
+		getStringArg(args, "grep", ""),
+		getBoolArg(args, "invert", false),
+		getStringArg(args, "level", ""),
+		int(getIntArg(args, "contextLines", 0)),
+	)
+	if err != nil {
+		return options, err
+	}
+	options.Filter = filter
+
+	if sinceTime := getStringArg(args, "sinceTime", ""); sinceTime != "" {
+		parsed, err := time.Parse(time.RFC3339, sinceTime)
+		if err != nil {
+			return options, fmt.Errorf("invalid sinceTime %q: must be an RFC3339 timestamp such as 2025-01-01T12:00:00Z", sinceTime)
+		}
+		options.SinceTime = &metav1.Time{Time: parsed}
+	}
+	return options, nil
+}
+
 // GetNodeMetrics returns a handler function for the getNodeMetrics tool.
 // It retrieves resource usage metrics for a specific node from the Kubernetes
 // cluster based on the provided node name. The result is serialized to JSON
 // and returned.
-func GetNodeMetrics(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func GetNodeMetrics(registry *cluster.Registry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]interface{})
 		if !ok {
 			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
 		}
 
+		client, err := k8sClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
 		// Using capital N to match your tools definition
 		name, err := getRequiredStringArg(args, "Name")
 		if err != nil {
@@ -248,13 +604,18 @@ func GetNodeMetrics(client *k8s.Client) func(ctx context.Context, request mcp.Ca
 // It retrieves CPU and Memory metrics for a specific pod from the Kubernetes
 // cluster based on the provided namespace and pod name. The result is
 // serialized to JSON and returned.
-func GetPodMetrics(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func GetPodMetrics(registry *cluster.Registry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]interface{})
 		if !ok {
 			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
 		}
 
+		client, err := k8sClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
 		namespace, err := getRequiredStringArg(args, "namespace")
 		if err != nil {
 			return nil, err
@@ -282,13 +643,18 @@ func GetPodMetrics(client *k8s.Client) func(ctx context.Context, request mcp.Cal
 // GetEvents returns a handler function for the getEvents tool.
 // It retrieves events from the Kubernetes cluster based on the provided
 // namespace and labelSelector. The result is serialized to JSON and returned.
-func GetEvents(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func GetEvents(registry *cluster.Registry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]interface{})
 		if !ok {
 			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
 		}
 
+		client, err := k8sClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
 		namespace := getStringArg(args, "namespace", "")
 
 		events, err := client.GetEvents(ctx, namespace)
@@ -308,13 +674,18 @@ func GetEvents(client *k8s.Client) func(ctx context.Context, request mcp.CallToo
 // CreateOrUpdateResource returns a handler function for the createOrUpdateResource tool.
 // It creates or updates a resource in the Kubernetes cluster based on the provided
 // namespace and manifest. The result is serialized to JSON and returned.
-func CreateOrUpdateResourceJSON(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func CreateOrUpdateResourceJSON(registry *cluster.Registry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]interface{})
 		if !ok {
 			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
 		}
 
+		client, err := k8sClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
 		manifest, err := getRequiredStringArg(args, "manifest")
 		if err != nil {
 			return nil, err
@@ -322,12 +693,20 @@ func CreateOrUpdateResourceJSON(client *k8s.Client) func(ctx context.Context, re
 
 		namespace := getStringArg(args, "namespace", "")
 		kind := getStringArg(args, "kind", "")
+		dryRun := getBoolArg(args, "dryRun", false)
 
-		resource, err := client.CreateOrUpdateResourceJSON(ctx, namespace, manifest, kind)
+		resource, note, err := client.CreateOrUpdateResourceJSON(ctx, namespace, manifest, kind, dryRun)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create or update resource: %w", err)
 		}
 
+		if dryRun && note != "" {
+			return dryRunResult(note+"; result is the object as it would be submitted", resource)
+		}
+		if dryRun {
+			return dryRunResult("The resource was validated by the API server but not persisted; result is the object as it would be stored", resource)
+		}
+
 		jsonResponse, err := json.Marshal(resource)
 		if err != nil {
 			return nil, fmt.Errorf("failed to serialize response: %w", err)
@@ -341,13 +720,18 @@ func CreateOrUpdateResourceJSON(client *k8s.Client) func(ctx context.Context, re
 // It creates or updates a resource in the Kubernetes cluster based on the provided
 // namespace and YAML manifest. This function is specifically optimized for YAML input.
 // The result is serialized to JSON and returned.
-func CreateOrUpdateResourceYAML(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func CreateOrUpdateResourceYAML(registry *cluster.Registry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]interface{})
 		if !ok {
 			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
 		}
 
+		client, err := k8sClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
 		yamlManifest, err := getRequiredStringArg(args, "manifest")
 		if err != nil {
 			return nil, err
@@ -355,12 +739,72 @@ func CreateOrUpdateResourceYAML(client *k8s.Client) func(ctx context.Context, re
 
 		namespace := getStringArg(args, "namespace", "")
 		kind := getStringArg(args, "kind", "")
+		dryRun := getBoolArg(args, "dryRun", false)
 
-		resource, err := client.CreateOrUpdateResourceYAML(ctx, namespace, yamlManifest, kind)
+		resource, err := client.CreateOrUpdateResourceYAML(ctx, namespace, yamlManifest, kind, dryRun)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create or update resource from YAML: %w", err)
 		}
 
+		if dryRun {
+			return dryRunResult("The resource was validated by the API server but not persisted; result is the object as it would be stored", resource)
+		}
+
+		jsonResponse, err := json.Marshal(resource)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// ApplyResource returns a handler function for the applyResource tool.
+// It applies the provided manifest with server-side apply as fieldManager.
+// When other field managers own fields in the manifest and force is not set,
+// the conflicting fields and their owners are returned as a tool error.
+func ApplyResource(registry *cluster.Registry, fieldManager string) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		client, err := k8sClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
+		manifest, err := getRequiredStringArg(args, "manifest")
+		if err != nil {
+			return nil, err
+		}
+
+		namespace := getStringArg(args, "namespace", "")
+		kind := getStringArg(args, "kind", "")
+		dryRun := getBoolArg(args, "dryRun", false)
+
+		resource, err := client.ApplyResource(ctx, namespace, manifest, kind, k8s.ApplyOptions{
+			FieldManager: fieldManager,
+			Force:        getBoolArg(args, "force", false),
+			DryRun:       dryRun,
+		})
+		var conflictErr *k8s.ApplyConflictError
+		if errors.As(err, &conflictErr) {
+			jsonConflicts, err := json.Marshal(conflictErr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to serialize response: %w", err)
+			}
+			return mcp.NewToolResultError(fmt.Sprintf("Apply rejected because fields are owned by other field managers. Remove the fields from the manifest, or set force to take ownership of them: %s", jsonConflicts)), nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply resource: %w", err)
+		}
+
+		if dryRun {
+			return dryRunResult("The apply was validated by the API server but not persisted; result is the object as it would be stored", resource)
+		}
+
 		jsonResponse, err := json.Marshal(resource)
 		if err != nil {
 			return nil, fmt.Errorf("failed to serialize response: %w", err)
@@ -370,16 +814,114 @@ func CreateOrUpdateResourceYAML(client *k8s.Client) func(ctx context.Context, re
 	}
 }
 
+// ApplyManifests returns a handler function for the applyManifests tool.
+// It applies every object of a multi-document manifest with server-side apply
+// as fieldManager and returns the outcome of each object, serialized to JSON.
+func ApplyManifests(registry *cluster.Registry, fieldManager string) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		client, err := k8sClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
+		manifest, err := getRequiredStringArg(args, "manifest")
+		if err != nil {
+			return nil, err
+		}
+
+		namespace := getStringArg(args, "namespace", "")
+		dryRun := getBoolArg(args, "dryRun", false)
+
+		results, err := client.ApplyManifests(ctx, namespace, manifest, k8s.ApplyOptions{
+			FieldManager: fieldManager,
+			Force:        getBoolArg(args, "force", false),
+			DryRun:       dryRun,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply manifests: %w", err)
+		}
+
+		summary := make(map[string]int)
+		for _, result := range results {
+			summary[result.Status]++
+		}
+		response := map[string]interface{}{
+			"summary": summary,
+			"results": results,
+		}
+
+		if dryRun {
+			return dryRunResult("The objects were validated by the API server but not persisted", response)
+		}
+
+		jsonResponse, err := json.Marshal(response)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
+// DiffResource returns a handler function for the diffResource tool.
+// It previews the changes applying a manifest as fieldManager would make to
+// the live object and returns the unified YAML diff, serialized to JSON.
+func DiffResource(registry *cluster.Registry, fieldManager string) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		args, ok := request.Params.Arguments.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
+		}
+
+		client, err := k8sClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
+		manifest, err := getRequiredStringArg(args, "manifest")
+		if err != nil {
+			return nil, err
+		}
+
+		namespace := getStringArg(args, "namespace", "")
+		kind := getStringArg(args, "kind", "")
+
+		diff, err := client.DiffResource(ctx, namespace, manifest, kind, fieldManager)
+		if err != nil {
+			return nil, fmt.Errorf("failed to diff resource: %w", err)
+		}
+
+		jsonResponse, err := json.Marshal(diff)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize response: %w", err)
+		}
+
+		return mcp.NewToolResultText(string(jsonResponse)), nil
+	}
+}
+
 // DeleteResource returns a handler function for the deleteResource tool.
 // It deletes a resource in the Kubernetes cluster based on the provided
 // namespace and kind. The result is serialized to JSON and returned.
-func DeleteResource(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+// When confirmations is set, the deletion only happens on a second call
+// carrying the confirmation token issued with the deletion impact.
+func DeleteResource(registry *cluster.Registry, confirmations *confirm.Store) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]interface{})
 		if !ok {
 			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
 		}
 
+		client, err := k8sClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
 		kind, err := getRequiredStringArg(args, "kind")
 		if err != nil {
 			return nil, err
@@ -391,12 +933,30 @@ func DeleteResource(client *k8s.Client) func(ctx context.Context, request mcp.Ca
 		}
 
 		namespace := getStringArg(args, "namespace", "")
+		dryRun := getBoolArg(args, "dryRun", false)
+
+		if !dryRun {
+			result, err := confirmDestructive(ctx, confirmations, request.Params.Name, args, func() (interface{}, error) {
+				return client.DeletionImpact(ctx, kind, name, namespace)
+			})
+			if result != nil || err != nil {
+				return result, err
+			}
+		}
 
-		err = client.DeleteResource(ctx, kind, name, namespace)
+		err = client.DeleteResource(ctx, kind, name, namespace, dryRun)
 		if err != nil {
 			return nil, fmt.Errorf("failed to delete resource: %w", err)
 		}
 
+		if dryRun {
+			resource, err := client.GetResource(ctx, kind, name, namespace, k8s.GetOptions{Strip: true})
+			if err != nil {
+				return nil, fmt.Errorf("failed to get resource: %w", err)
+			}
+			return dryRunResult("The deletion was validated by the API server but not performed; result is the resource that would be deleted", resource)
+		}
+
 		return mcp.NewToolResultText("Resource deleted successfully"), nil
 	}
 }
@@ -404,13 +964,18 @@ func DeleteResource(client *k8s.Client) func(ctx context.Context, request mcp.Ca
 // getIngresses returns a handler function for the getIngresses tool.
 // It retrieves ingress resources from the Kubernetes cluster based on the provided
 // Host and Path. The result is serialized to JSON and returned.
-func GetIngresses(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func GetIngresses(registry *cluster.Registry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]interface{})
 		if !ok {
 			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
 		}
 
+		client, err := k8sClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
 		host := getStringArg(args, "host", "")
 
 		ingresses, err := client.GetIngresses(ctx, host)
@@ -429,13 +994,18 @@ func GetIngresses(client *k8s.Client) func(ctx context.Context, request mcp.Call
 
 // RolloutRestartHandler returns a handler function for the rolloutRestart tool.
 // It calls the Client.RolloutRestart method and serializes the result to JSON.
-func RolloutRestart(client *k8s.Client) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+func RolloutRestart(registry *cluster.Registry) func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 		args, ok := request.Params.Arguments.(map[string]interface{})
 		if !ok {
 			return nil, fmt.Errorf("invalid arguments type: expected map[string]interface{}")
 		}
 
+		client, err := k8sClientFor(ctx, registry, args)
+		if err != nil {
+			return nil, err
+		}
+
 		kind := getStringArg(args, "kind", "")
 		name := getStringArg(args, "name", "")
 		namespace := getStringArg(args, "namespace", "")
@@ -444,11 +1014,17 @@ func RolloutRestart(client *k8s.Client) func(ctx context.Context, request mcp.Ca
 			return nil, fmt.Errorf("kind, name, and namespace are required")
 		}
 
-		result, err := client.RolloutRestart(ctx, kind, name, namespace)
+		dryRun := getBoolArg(args, "dryRun", false)
+
+		result, err := client.RolloutRestart(ctx, kind, name, namespace, dryRun)
 		if err != nil {
 			return nil, fmt.Errorf("failed to rollout restart resource: %w", err)
 		}
 
+		if dryRun {
+			return dryRunResult("The restart was validated by the API server but not persisted; result is the workload as it would be stored", result)
+		}
+
 		jsonResponse, err := json.Marshal(result)
 		if err != nil {
 			return nil, fmt.Errorf("failed to serialize response: %w", err)
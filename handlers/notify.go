@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// logLevels maps the levels of log lines to MCP logging levels.
+var logLevels = map[int]mcp.LoggingLevel{
+	k8s.LogLevelDebug: mcp.LoggingLevelDebug,
+	k8s.LogLevelInfo:  mcp.LoggingLevelInfo,
+	k8s.LogLevelWarn:  mcp.LoggingLevelWarning,
+	k8s.LogLevelError: mcp.LoggingLevelError,
+	k8s.LogLevelFatal: mcp.LoggingLevelCritical,
+}
+
+// logNotifier pushes log lines to the client of a tool call while it runs.
+// If the request carries a progress token, every line is sent as a
+// notifications/progress message. Otherwise it is sent as a
+// notifications/message log message at the level of the line, which the
+// client receives according to the level it set with logging/setLevel.
+type logNotifier struct {
+	server        *server.MCPServer
+	progressToken mcp.ProgressToken
+	logger        string
+	// lines counts the lines notified, dropped those that could not be sent
+	// because the client's notification queue was full.
+	lines   int
+	dropped int
+}
+
+// newLogNotifier creates a notifier for the tool call of request. Lines are
+// logged under the logger name, such as the name of the pod.
+func newLogNotifier(ctx context.Context, request mcp.CallToolRequest, logger string) *logNotifier {
+	notifier := &logNotifier{server: server.ServerFromContext(ctx), logger: logger}
+	if request.Params.Meta != nil {
+		notifier.progressToken = request.Params.Meta.ProgressToken
+	}
+	return notifier
+}
+
+// notify sends a log line to the client. Lines are never waited for: if
+// the client cannot keep up, they are dropped and counted.
+func (n *logNotifier) notify(ctx context.Context, line string) {
+	if n.server == nil {
+		return
+	}
+
+	n.lines++
+	var err error
+	if n.progressToken != nil {
+		err = n.server.SendNotificationToClient(ctx, "notifications/progress", map[string]any{
+			"progressToken": n.progressToken,
+			"progress":      n.lines,
+			"message":       line,
+		})
+	} else {
+		level, ok := logLevels[k8s.LogLevel(line)]
+		if !ok {
+			level = mcp.LoggingLevelInfo
+		}
+		err = n.server.SendLogMessageToClient(ctx, mcp.NewLoggingMessageNotification(level, n.logger, line))
+	}
+
+	if errors.Is(err, server.ErrNotificationChannelBlocked) {
+		n.dropped++
+	}
+}
@@ -10,13 +10,25 @@
 package main
 
 import (
+	"crypto/tls"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/reza-gholizade/k8s-mcp-server/handlers"
-	"github.com/reza-gholizade/k8s-mcp-server/pkg/helm"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/audit"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/auth"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/cluster"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/confirm"
 	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/kubeconfig"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/policy"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/redact"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/tlsconfig"
 	"github.com/reza-gholizade/k8s-mcp-server/tools"
 
 	"github.com/mark3labs/mcp-go/server"
@@ -29,14 +41,55 @@ func main() {
 	var mode string
 	var port string
 	var readOnly bool
+	var dryRun bool
+	var requireConfirmation bool
 	var noK8s bool
 	var noHelm bool
+	var kubeconfigPath string
+	var kubeContext string
+	var inCluster bool
+	var impersonate bool
+	var authConfig auth.Config
+	var tlsOptions tlsconfig.Options
+	var auditOptions audit.Options
+	var policyFile string
+	var fieldManager string
+	var discoveryTTL time.Duration
+	var listMaxItems int
+	var revealSecrets bool
+	var redactKeys string
 
 	flag.StringVar(&port, "port", getEnvOrDefault("SERVER_PORT", "8080"), "Server port")
 	flag.StringVar(&mode, "mode", getEnvOrDefault("SERVER_MODE", "sse"), "Server mode: 'stdio', 'sse', or 'streamable-http'")
 	flag.BoolVar(&readOnly, "read-only", false, "Enable read-only mode (disables write operations)")
+	flag.BoolVar(&dryRun, "dry-run", getEnvBoolOrDefault("DRY_RUN", false), "Run every mutating tool as a server-side dry run (nothing is changed)")
+	flag.BoolVar(&requireConfirmation, "require-confirmation", getEnvBoolOrDefault("REQUIRE_CONFIRMATION", false), "Require a confirmation token, issued with an impact summary, before deleting resources or uninstalling releases (a soft safeguard: the model receives the token and can replay it)")
 	flag.BoolVar(&noK8s, "no-k8s", false, "Disable Kubernetes tools")
 	flag.BoolVar(&noHelm, "no-helm", false, "Disable Helm tools")
+	flag.StringVar(&kubeconfigPath, "kubeconfig", "", "Path to the kubeconfig file, or a list of paths like KUBECONFIG (defaults to KUBECONFIG, then ~/.kube/config)")
+	flag.StringVar(&kubeContext, "context", getEnvOrDefault("KUBERNETES_CONTEXT", ""), "Kubeconfig context to use when a tool does not request one (defaults to the current context)")
+	flag.BoolVar(&inCluster, "in-cluster", getEnvBoolOrDefault("KUBERNETES_IN_CLUSTER", false), "Use the pod's service account instead of a kubeconfig")
+	flag.BoolVar(&impersonate, "impersonate", getEnvBoolOrDefault("KUBERNETES_IMPERSONATE", false), "Impersonate the authenticated caller on every Kubernetes API call (requires HTTP authentication)")
+	flag.StringVar(&authConfig.TokenFile, "auth-token-file", getEnvOrDefault("AUTH_TOKEN_FILE", ""), "Static bearer token file for the HTTP transports (token,user,uid,\"group1,group2\")")
+	flag.StringVar(&authConfig.OIDC.Issuer, "oidc-issuer", getEnvOrDefault("OIDC_ISSUER", ""), "OIDC issuer URL; enables JWT bearer token authentication for the HTTP transports")
+	flag.StringVar(&authConfig.OIDC.Audience, "oidc-audience", getEnvOrDefault("OIDC_AUDIENCE", ""), "Expected audience of OIDC tokens")
+	flag.StringVar(&authConfig.OIDC.JWKSFile, "oidc-jwks-file", getEnvOrDefault("OIDC_JWKS_FILE", ""), "Path of the JWKS used to verify OIDC tokens")
+	flag.StringVar(&authConfig.OIDC.JWKSURL, "oidc-jwks-url", getEnvOrDefault("OIDC_JWKS_URL", ""), "URL of the JWKS used to verify OIDC tokens (defaults to the issuer's discovery document)")
+	flag.StringVar(&authConfig.OIDC.UsernameClaim, "oidc-username-claim", getEnvOrDefault("OIDC_USERNAME_CLAIM", "sub"), "OIDC token claim used as the username")
+	flag.StringVar(&authConfig.OIDC.GroupsClaim, "oidc-groups-claim", getEnvOrDefault("OIDC_GROUPS_CLAIM", "groups"), "OIDC token claim used as the user's groups")
+	flag.StringVar(&tlsOptions.CertFile, "tls-cert", getEnvOrDefault("TLS_CERT_FILE", ""), "TLS certificate file; enables HTTPS for the HTTP transports")
+	flag.StringVar(&tlsOptions.KeyFile, "tls-key", getEnvOrDefault("TLS_KEY_FILE", ""), "TLS private key file")
+	flag.StringVar(&tlsOptions.ClientCAFile, "client-ca", getEnvOrDefault("TLS_CLIENT_CA_FILE", ""), "CA bundle used to verify client certificates; verified certificates authenticate the caller (CN as user, O as groups)")
+	flag.BoolVar(&tlsOptions.RequireClientCert, "require-client-cert", getEnvBoolOrDefault("TLS_REQUIRE_CLIENT_CERT", false), "Reject TLS connections without a valid client certificate (requires --client-ca)")
+	flag.StringVar(&auditOptions.Path, "audit-log", getEnvOrDefault("AUDIT_LOG", ""), "Audit log file for tool invocations, or '-' for stdout (disabled when empty)")
+	flag.IntVar(&auditOptions.MaxSizeMB, "audit-log-max-size", getEnvIntOrDefault("AUDIT_LOG_MAX_SIZE", 100), "Size in megabytes at which the audit log is rotated (0 disables rotation)")
+	flag.IntVar(&auditOptions.MaxBackups, "audit-log-max-backups", getEnvIntOrDefault("AUDIT_LOG_MAX_BACKUPS", 5), "Number of rotated audit log files to keep")
+	flag.StringVar(&policyFile, "policy-file", getEnvOrDefault("POLICY_FILE", ""), "YAML policy file allowing or denying tool calls by tool, kind, namespace, context, caller and arguments")
+	flag.StringVar(&fieldManager, "field-manager", getEnvOrDefault("FIELD_MANAGER", k8s.DefaultFieldManager), "Field manager name used for server-side apply")
+	flag.DurationVar(&discoveryTTL, "discovery-ttl", getEnvDurationOrDefault("DISCOVERY_TTL", k8s.DefaultDiscoveryTTL), "How long API discovery is cached before it is fetched again (0 caches until refreshDiscovery or a Helm change)")
+	flag.IntVar(&listMaxItems, "list-max-items", getEnvIntOrDefault("LIST_MAX_ITEMS", 500), "Maximum number of items listResources returns per page (0 for no limit)")
+	flag.BoolVar(&revealSecrets, "reveal-secrets", getEnvBoolOrDefault("REVEAL_SECRETS", false), "Return Secret data and other sensitive values in tool results instead of masking them")
+	flag.StringVar(&redactKeys, "redact-keys", getEnvOrDefault("REDACT_KEYS", strings.Join(redact.DefaultKeyPatterns, ",")), "Comma-separated, case-insensitive regular expressions of keys whose string values are masked in tool results and the audit log")
 	flag.Parse()
 
 	// Validate flag combinations
@@ -44,12 +97,51 @@ func main() {
 		fmt.Println("Error: Cannot disable both Kubernetes and Helm tools. At least one tool category must be enabled.")
 		os.Exit(1)
 	}
+	if listMaxItems < 0 {
+		fmt.Println("Error: --list-max-items must not be negative")
+		os.Exit(1)
+	}
+	if auditOptions.Path == "-" && mode == "stdio" {
+		fmt.Println("Error: the audit log cannot be written to stdout in stdio mode; use a file instead")
+		os.Exit(1)
+	}
+
+	// Set up TLS for the HTTP transports; certificates are reloaded when they change on disk
+	var tlsConfig *tls.Config
+	if tlsOptions.CertFile != "" || tlsOptions.KeyFile != "" || tlsOptions.ClientCAFile != "" {
+		reloader, err := tlsconfig.NewReloader(tlsOptions)
+		if err != nil {
+			fmt.Printf("Error: failed to configure TLS: %v\n", err)
+			os.Exit(1)
+		}
+		tlsConfig = reloader.TLSConfig()
+		authConfig.ClientCertificates = tlsOptions.ClientCAFile != ""
+	}
+
+	// Set up authentication for the HTTP transports
+	authenticator, err := auth.NewAuthenticator(authConfig)
+	if err != nil {
+		fmt.Printf("Error: failed to configure authentication: %v\n", err)
+		os.Exit(1)
+	}
+	if impersonate && (authenticator == nil || mode == "stdio") {
+		fmt.Println("Error: --impersonate requires the sse or streamable-http mode with authentication configured")
+		os.Exit(1)
+	}
+	if authenticator == nil && mode != "stdio" {
+		fmt.Println("Warning: no authentication configured - anyone who can reach the server can use all tools")
+	}
 
 	// Log read-only mode status
 	if readOnly {
 		fmt.Println("Starting server in read-only mode - write operations disabled")
 	}
 
+	// Log dry-run mode status
+	if dryRun {
+		fmt.Println("Starting server in dry-run mode - mutating tools only validate their changes")
+	}
+
 	// Log disabled tool categories
 	if noK8s {
 		fmt.Println("Kubernetes tools disabled")
@@ -58,62 +150,136 @@ func main() {
 		fmt.Println("Helm tools disabled")
 	}
 
-	// Create MCP server
-	s := server.NewMCPServer(
-		"MCP K8S & Helm Server",
-		"1.0.0",
-		server.WithResourceCapabilities(true, true), // Enable resource listing and subscription capabilities
-	)
-
-	// Create a Kubernetes client
-	client, err := k8s.NewClient("")
+	// Create the shared kubeconfig loader used by both the Kubernetes and Helm clients
+	loader, err := kubeconfig.NewLoader(kubeconfig.Options{
+		Kubeconfig: kubeconfigPath,
+		Context:    kubeContext,
+		InCluster:  inCluster,
+	})
 	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Create a registry of per-context Kubernetes and Helm clients
+	registry := cluster.NewRegistry(loader, impersonate, discoveryTTL)
+	if impersonate {
+		fmt.Println("Impersonating authenticated callers - Kubernetes RBAC applies to each caller")
+	}
+
+	// Make sure the default context is usable before serving requests
+	if err := registry.Validate(); err != nil {
 		fmt.Printf("Failed to create Kubernetes client: %v\n", err)
 		return
 	}
 
-	// Create Helm client with default kubeconfig path
-	helmClient, err := helm.NewClient("")
+	serverOptions := []server.ServerOption{
+		server.WithResourceCapabilities(true, true), // Enable resource listing and subscription capabilities
+		server.WithLogging(),                        // Let clients receive followed logs as log messages
+	}
+
+	// The same key patterns mask secrets in tool results and in the audit log
+	redactor, err := redact.New(strings.Split(redactKeys, ","))
 	if err != nil {
-		fmt.Printf("Failed to create Helm client: %v\n", err)
-		return
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Force dry runs first, so the audit log records the arguments the handlers see
+	if dryRun {
+		serverOptions = append(serverOptions, server.WithToolHandlerMiddleware(handlers.ForceDryRun()))
 	}
 
+	// Record every tool invocation in the audit log
+	if auditOptions.Path != "" {
+		auditOptions.Redactor = redactor
+		auditLogger, err := audit.NewLogger(auditOptions, registry)
+		if err != nil {
+			fmt.Printf("Error: failed to open audit log: %v\n", err)
+			os.Exit(1)
+		}
+		defer auditLogger.Close()
+		serverOptions = append(serverOptions, server.WithToolHandlerMiddleware(auditLogger.Middleware()))
+		fmt.Printf("Writing audit log to %s\n", auditOptions.Path)
+	}
+
+	// Evaluate the tool policy before any handler runs; denials are still audited
+	if policyFile != "" {
+		toolPolicy, err := policy.Load(policyFile)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		serverOptions = append(serverOptions, server.WithToolHandlerMiddleware(policy.Middleware(toolPolicy, registry)))
+		fmt.Printf("Enforcing tool policy from %s (%d rules, default %s)\n", policyFile, len(toolPolicy.Rules), toolPolicy.Default)
+	}
+
+	// Mask secrets in every tool result before it reaches the model
+	if revealSecrets {
+		fmt.Println("Warning: secret redaction disabled - Secret data and sensitive values are returned to the model")
+	} else {
+		serverOptions = append(serverOptions, server.WithToolHandlerMiddleware(redactor.Middleware()))
+	}
+
+	// Destructive tools return an impact summary and a confirmation token first
+	var confirmations *confirm.Store
+	if requireConfirmation {
+		confirmations = confirm.NewStore(confirm.DefaultTTL)
+	}
+
+	// Create MCP server
+	s := server.NewMCPServer(
+		"MCP K8S & Helm Server",
+		"1.0.0",
+		serverOptions...,
+	)
+
+	// Register kubeconfig context tools
+	s.AddTool(tools.ListContextsTool(), handlers.ListContexts(registry))
+	s.AddTool(tools.GetCurrentContextTool(), handlers.GetCurrentContext(registry))
+
 	// Register Kubernetes tools
 	if !noK8s {
-		s.AddTool(tools.GetAPIResourcesTool(), handlers.GetAPIResources(client))
-		s.AddTool(tools.ListResourcesTool(), handlers.ListResources(client))
-		s.AddTool(tools.GetResourcesTool(), handlers.GetResources(client))
-		s.AddTool(tools.DescribeResourcesTool(), handlers.DescribeResources(client))
-		s.AddTool(tools.GetPodsLogsTools(), handlers.GetPodsLogs(client))
-		s.AddTool(tools.GetNodeMetricsTools(), handlers.GetNodeMetrics(client))
-		s.AddTool(tools.GetPodMetricsTool(), handlers.GetPodMetrics(client))
-		s.AddTool(tools.GetEventsTool(), handlers.GetEvents(client))
-		s.AddTool(tools.GetIngressesTool(), handlers.GetIngresses(client))
+		s.AddTool(tools.GetAPIResourcesTool(), handlers.GetAPIResources(registry))
+		s.AddTool(tools.RefreshDiscoveryTool(), handlers.RefreshDiscovery(registry))
+		s.AddTool(tools.ListResourcesTool(), handlers.ListResources(registry, int64(listMaxItems)))
+		s.AddTool(tools.GetResourcesTool(), handlers.GetResources(registry))
+		s.AddTool(tools.DescribeResourcesTool(), handlers.DescribeResources(registry))
+		s.AddTool(tools.GetPodsLogsTools(), handlers.GetPodsLogs(registry))
+		s.AddTool(tools.GetWorkloadLogsTool(), handlers.GetWorkloadLogs(registry))
+		s.AddTool(tools.FollowPodLogsTool(), handlers.FollowPodLogs(registry))
+		s.AddTool(tools.SummarizeLogsTool(), handlers.SummarizeLogs(registry))
+		s.AddTool(tools.GetNodeMetricsTools(), handlers.GetNodeMetrics(registry))
+		s.AddTool(tools.GetPodMetricsTool(), handlers.GetPodMetrics(registry))
+		s.AddTool(tools.GetEventsTool(), handlers.GetEvents(registry))
+		s.AddTool(tools.GetIngressesTool(), handlers.GetIngresses(registry))
+		s.AddTool(tools.DiffResourceTool(), handlers.DiffResource(registry, fieldManager))
 
 		// Register write operations only if not in read-only mode
 		if !readOnly {
-			s.AddTool(tools.CreateOrUpdateResourceJSONTool(), handlers.CreateOrUpdateResourceJSON(client))
-			s.AddTool(tools.CreateOrUpdateResourceYAMLTool(), handlers.CreateOrUpdateResourceYAML(client))
-			s.AddTool(tools.DeleteResourceTool(), handlers.DeleteResource(client))
-			s.AddTool(tools.RolloutRestartTool(), handlers.RolloutRestart(client))
+			s.AddTool(tools.CreateOrUpdateResourceJSONTool(), handlers.CreateOrUpdateResourceJSON(registry))
+			s.AddTool(tools.CreateOrUpdateResourceYAMLTool(), handlers.CreateOrUpdateResourceYAML(registry))
+			s.AddTool(tools.ApplyResourceTool(), handlers.ApplyResource(registry, fieldManager))
+			s.AddTool(tools.ApplyManifestsTool(), handlers.ApplyManifests(registry, fieldManager))
+			s.AddTool(tools.DeleteResourceTool(), handlers.DeleteResource(registry, confirmations))
+			s.AddTool(tools.RolloutRestartTool(), handlers.RolloutRestart(registry))
 		}
 	}
 
 	// Register Helm tools
 	if !noHelm {
-		s.AddTool(tools.HelmListTool(), handlers.HelmList(helmClient))
-		s.AddTool(tools.HelmGetTool(), handlers.HelmGet(helmClient))
-		s.AddTool(tools.HelmHistoryTool(), handlers.HelmHistory(helmClient))
-		s.AddTool(tools.HelmRepoListTool(), handlers.HelmRepoList(helmClient))
+		s.AddTool(tools.HelmListTool(), handlers.HelmList(registry))
+		s.AddTool(tools.HelmGetTool(), handlers.HelmGet(registry))
+		s.AddTool(tools.HelmHistoryTool(), handlers.HelmHistory(registry))
+		s.AddTool(tools.HelmRepoListTool(), handlers.HelmRepoList(registry))
 
 		// Register write operations only if not in read-only mode
 		if !readOnly {
-			s.AddTool(tools.HelmInstallTool(), handlers.HelmInstall(helmClient))
-			s.AddTool(tools.HelmUpgradeTool(), handlers.HelmUpgrade(helmClient))
-			s.AddTool(tools.HelmUninstallTool(), handlers.HelmUninstall(helmClient))
-			s.AddTool(tools.HelmRollbackTool(), handlers.HelmRollback(helmClient))
-			s.AddTool(tools.HelmRepoAddTool(), handlers.HelmRepoAdd(helmClient))
+			s.AddTool(tools.HelmInstallTool(), handlers.HelmInstall(registry))
+			s.AddTool(tools.HelmUpgradeTool(), handlers.HelmUpgrade(registry))
+			s.AddTool(tools.HelmUninstallTool(), handlers.HelmUninstall(registry, confirmations))
+			s.AddTool(tools.HelmRollbackTool(), handlers.HelmRollback(registry))
+			s.AddTool(tools.HelmRepoAddTool(), handlers.HelmRepoAdd(registry))
 		}
 	}
 
@@ -128,7 +294,7 @@ func main() {
 	case "sse":
 		fmt.Printf("Starting server in SSE mode on port %s...\n", port)
 		sse := server.NewSSEServer(s)
-		if err := sse.Start(":" + port); err != nil {
+		if err := serveHTTP(":"+port, sse, authenticator, tlsConfig); err != nil {
 			fmt.Printf("Failed to start SSE server: %v\n", err)
 			return
 		}
@@ -136,17 +302,47 @@ func main() {
 	case "streamable-http":
 		fmt.Printf("Starting server in streamable-http mode on port %s...\n", port)
 		streamableHTTP := server.NewStreamableHTTPServer(s, server.WithStateLess(true))
-		if err := streamableHTTP.Start(":" + port); err != nil {
+		mux := http.NewServeMux()
+		mux.Handle("/mcp", streamableHTTP)
+		if err := serveHTTP(":"+port, mux, authenticator, tlsConfig); err != nil {
 			fmt.Printf("Failed to start streamable-http server: %v\n", err)
 			return
 		}
-		fmt.Printf("Streamable-http server started on port %s (endpoint: http://localhost:%s/mcp)\n", port, port)
+		fmt.Printf("Streamable-http server started on port %s (endpoint: %s://localhost:%s/mcp)\n", port, scheme(tlsConfig), port)
 	default:
 		fmt.Printf("Unknown server mode: %s. Use 'stdio', 'sse', or 'streamable-http'.\n", mode)
 		return
 	}
 }
 
+// serveHTTP serves handler on addr. When an authenticator is configured,
+// every request must be authenticated and the caller's identity is attached
+// to the request context. When tlsConfig is set, the server terminates TLS itself.
+func serveHTTP(addr string, handler http.Handler, authenticator auth.Authenticator, tlsConfig *tls.Config) error {
+	if authenticator != nil {
+		handler = auth.Middleware(authenticator, handler)
+	}
+
+	srv := &http.Server{
+		Addr:      addr,
+		Handler:   handler,
+		TLSConfig: tlsConfig,
+	}
+	if tlsConfig != nil {
+		// The certificate is provided by tlsConfig, so no files are passed here
+		return srv.ListenAndServeTLS("", "")
+	}
+	return srv.ListenAndServe()
+}
+
+// scheme returns the URL scheme of the HTTP transports.
+func scheme(tlsConfig *tls.Config) string {
+	if tlsConfig != nil {
+		return "https"
+	}
+	return "http"
+}
+
 // getEnvOrDefault returns the value of the environment variable or the default value if not set
 func getEnvOrDefault(key, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
@@ -154,3 +350,33 @@ func getEnvOrDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvBoolOrDefault returns the boolean value of the environment variable or the default value if not set or invalid
+func getEnvBoolOrDefault(key string, defaultValue bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getEnvIntOrDefault returns the integer value of the environment variable or the default value if not set or invalid
+func getEnvIntOrDefault(key string, defaultValue int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
+
+// getEnvDurationOrDefault returns the duration value of the environment variable or the default value if not set or invalid
+func getEnvDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	return defaultValue
+}
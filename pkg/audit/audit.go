@@ -0,0 +1,212 @@
+// Package audit records every tool invocation as a JSON line: who called
+// which tool, with which (redacted) arguments, against which cluster and
+// namespace, and how it ended.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/auth"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/cluster"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/redact"
+)
+
+// Outcomes recorded in Event.Outcome.
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+	OutcomeDenied  = "denied"
+)
+
+// Event is a single audit record.
+type Event struct {
+	Time       time.Time              `json:"time"`
+	User       *auth.Identity         `json:"user,omitempty"`
+	Tool       string                 `json:"tool"`
+	Arguments  map[string]interface{} `json:"arguments,omitempty"`
+	Context    string                 `json:"context,omitempty"`
+	Cluster    string                 `json:"cluster,omitempty"`
+	Namespace  string                 `json:"namespace,omitempty"`
+	Outcome    string                 `json:"outcome"`
+	Error      string                 `json:"error,omitempty"`
+	DurationMS int64                  `json:"durationMs"`
+}
+
+type outcomeKey struct{}
+
+// SetOutcome overrides the outcome recorded for the tool call running in ctx.
+// Inner middlewares use it to record why a call did not reach its handler.
+func SetOutcome(ctx context.Context, outcome string) {
+	if slot, ok := ctx.Value(outcomeKey{}).(*string); ok {
+		*slot = outcome
+	}
+}
+
+// Options configures the audit log sink.
+type Options struct {
+	// Path is the audit log file, or "-" for stdout.
+	Path string
+	// MaxSizeMB is the size in megabytes at which the file is rotated.
+	// Zero disables rotation. It is ignored for stdout.
+	MaxSizeMB int
+	// MaxBackups is the number of rotated files to keep.
+	MaxBackups int
+	// Redactor masks secrets in the recorded arguments. It should be built
+	// from the same key patterns as the redactor of tool results; the
+	// default patterns are used if it is nil.
+	Redactor *redact.Redactor
+}
+
+// Logger writes audit events to a sink.
+type Logger struct {
+	registry *cluster.Registry
+	redactor *redact.Redactor
+	writer   io.Writer
+	lock     sync.Mutex
+}
+
+// NewLogger opens the audit log described by options.
+// The registry resolves the cluster targeted by each invocation.
+func NewLogger(options Options, registry *cluster.Registry) (*Logger, error) {
+	if options.Path == "" {
+		return nil, fmt.Errorf("an audit log path is required")
+	}
+
+	redactor := options.Redactor
+	if redactor == nil {
+		var err error
+		if redactor, err = redact.New(redact.DefaultKeyPatterns); err != nil {
+			return nil, err
+		}
+	}
+
+	var writer io.Writer = os.Stdout
+	if options.Path != "-" {
+		file, err := newRotatingFile(options.Path, int64(options.MaxSizeMB)*1024*1024, options.MaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		writer = file
+	}
+	return &Logger{registry: registry, redactor: redactor, writer: writer}, nil
+}
+
+// Close closes the underlying file, if any.
+func (l *Logger) Close() error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if closer, ok := l.writer.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Middleware returns a tool handler middleware that records every invocation.
+func (l *Logger) Middleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			start := time.Now()
+			var outcome string
+			result, err := next(context.WithValue(ctx, outcomeKey{}, &outcome), request)
+
+			event := l.newEvent(ctx, request, start, result, err)
+			if outcome != "" {
+				event.Outcome = outcome
+			}
+			l.Log(event)
+			return result, err
+		}
+	}
+}
+
+// Log writes a single event. Write failures are reported on stderr, which
+// unlike stdout does not carry the stdio transport, but never fail the tool call.
+func (l *Logger) Log(event *Event) {
+	line, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed to encode audit event: %v", err)
+		return
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if _, err := l.writer.Write(append(line, '\n')); err != nil {
+		log.Printf("Failed to write audit event: %v", err)
+	}
+}
+
+// newEvent builds the audit record of a finished tool call.
+func (l *Logger) newEvent(ctx context.Context, request mcp.CallToolRequest, start time.Time, result *mcp.CallToolResult, err error) *Event {
+	args, _ := request.Params.Arguments.(map[string]interface{})
+	redacted := Redact(l.redactor, args)
+
+	event := &Event{
+		Time:       start.UTC(),
+		Tool:       request.Params.Name,
+		Arguments:  redacted,
+		Outcome:    OutcomeSuccess,
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+	if identity, ok := auth.IdentityFromContext(ctx); ok {
+		event.User = identity
+	}
+
+	contextName, _ := args["context"].(string)
+	event.Context = contextName
+	if l.registry != nil {
+		if info, err := l.registry.Context(contextName); err == nil {
+			event.Context = info.Name
+			event.Cluster = info.Cluster
+		}
+	}
+	event.Namespace = targetNamespace(redacted)
+
+	switch {
+	case err != nil:
+		event.Outcome = OutcomeError
+		event.Error = err.Error()
+	case result != nil && result.IsError:
+		event.Outcome = OutcomeError
+		event.Error = resultText(result)
+	}
+	return event
+}
+
+// targetNamespace returns the namespace argument, falling back to the
+// namespace of a manifest argument.
+func targetNamespace(args map[string]interface{}) string {
+	if namespace, ok := args["namespace"].(string); ok && namespace != "" {
+		return namespace
+	}
+	for _, key := range manifestArguments {
+		manifest, ok := args[key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if metadata, ok := manifest["metadata"].(map[string]interface{}); ok {
+			if namespace, ok := metadata["namespace"].(string); ok {
+				return namespace
+			}
+		}
+	}
+	return ""
+}
+
+// resultText returns the text content of a tool result.
+func resultText(result *mcp.CallToolResult) string {
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			return text.Text
+		}
+	}
+	return ""
+}
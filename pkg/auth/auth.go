@@ -0,0 +1,142 @@
+// Package auth provides pluggable authentication for the HTTP transports.
+// Authenticated identities are attached to the request context so that tool
+// handlers can find out who is calling them.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrNoCredentials is returned when a request carries no credentials at all.
+var ErrNoCredentials = errors.New("no credentials provided")
+
+// Identity describes an authenticated caller.
+type Identity struct {
+	Username string   `json:"username"`
+	UID      string   `json:"uid,omitempty"`
+	Groups   []string `json:"groups,omitempty"`
+}
+
+// Authenticator authenticates an HTTP request.
+// It returns the caller's identity, or an error if the request could not be authenticated.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Identity, error)
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a copy of ctx carrying the given identity.
+func WithIdentity(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the identity attached to ctx, if any.
+func IdentityFromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok && identity != nil
+}
+
+// Middleware rejects unauthenticated requests with 401 Unauthorized and
+// attaches the identity of authenticated callers to the request context.
+func Middleware(authenticator Authenticator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, err := authenticator.Authenticate(r)
+		if err != nil {
+			if !errors.Is(err, ErrNoCredentials) {
+				fmt.Printf("Authentication failed for %s: %s\n", r.RemoteAddr, strings.ReplaceAll(err.Error(), "\n", "; "))
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer realm="k8s-mcp-server"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), identity)))
+	})
+}
+
+// unionAuthenticator tries each authenticator in order and returns the first success.
+type unionAuthenticator []Authenticator
+
+// NewUnionAuthenticator returns an Authenticator that accepts a request if any
+// of the given authenticators accepts it.
+func NewUnionAuthenticator(authenticators ...Authenticator) Authenticator {
+	if len(authenticators) == 1 {
+		return authenticators[0]
+	}
+	return unionAuthenticator(authenticators)
+}
+
+// Authenticate implements Authenticator.
+func (u unionAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	var errs []error
+	for _, authenticator := range u {
+		identity, err := authenticator.Authenticate(r)
+		if err == nil {
+			return identity, nil
+		}
+		if !errors.Is(err, ErrNoCredentials) {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil, ErrNoCredentials
+	}
+	return nil, errors.Join(errs...)
+}
+
+// bearerToken extracts the bearer token from the Authorization header.
+func bearerToken(r *http.Request) (string, error) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return "", ErrNoCredentials
+	}
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+		return "", ErrNoCredentials
+	}
+	return strings.TrimSpace(token), nil
+}
+
+// Config holds the authentication settings of the HTTP transports.
+type Config struct {
+	// ClientCertificates enables authentication with verified TLS client certificates.
+	ClientCertificates bool
+	// TokenFile is the path of a static token file.
+	TokenFile string
+	// OIDC configures JWT validation. It is disabled when OIDC.Issuer is empty.
+	OIDC JWTConfig
+}
+
+// NewAuthenticator builds an Authenticator from cfg.
+// It returns nil if no authentication method is configured.
+func NewAuthenticator(cfg Config) (Authenticator, error) {
+	var authenticators []Authenticator
+
+	if cfg.ClientCertificates {
+		authenticators = append(authenticators, NewClientCertAuthenticator())
+	}
+
+	if cfg.TokenFile != "" {
+		tokenAuthenticator, err := NewTokenFileAuthenticator(cfg.TokenFile)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, tokenAuthenticator)
+	}
+
+	if cfg.OIDC.Issuer != "" {
+		jwtAuthenticator, err := NewJWTAuthenticator(cfg.OIDC)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, jwtAuthenticator)
+	}
+
+	if len(authenticators) == 0 {
+		return nil, nil
+	}
+	return NewUnionAuthenticator(authenticators...), nil
+}
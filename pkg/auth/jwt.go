@@ -0,0 +1,462 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256" // register SHA-256 for crypto.Hash
+	_ "crypto/sha512" // register SHA-384 and SHA-512 for crypto.Hash
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// clockSkew is the leeway allowed when checking exp and nbf.
+	clockSkew = time.Minute
+	// jwksRefreshInterval is how often a remote JWKS is re-fetched.
+	jwksRefreshInterval = time.Hour
+	// jwksMinRefreshInterval rate-limits re-fetches triggered by unknown key IDs.
+	jwksMinRefreshInterval = time.Minute
+)
+
+// JWTConfig configures OIDC/JWT bearer token validation.
+type JWTConfig struct {
+	// Issuer is the expected "iss" claim.
+	Issuer string
+	// Audience is the expected "aud" claim. It is not checked when empty.
+	Audience string
+	// JWKSFile is the path of a JSON Web Key Set used to verify signatures.
+	JWKSFile string
+	// JWKSURL is the URL of a JSON Web Key Set. When neither JWKSFile nor
+	// JWKSURL is set, the jwks_uri from the issuer's discovery document is used.
+	JWKSURL string
+	// UsernameClaim is the claim used as the username. Defaults to "sub".
+	UsernameClaim string
+	// GroupsClaim is the claim used as the list of groups. Defaults to "groups".
+	GroupsClaim string
+}
+
+// JWTAuthenticator validates JWT bearer tokens issued by an OIDC provider.
+type JWTAuthenticator struct {
+	config JWTConfig
+	keys   *keySet
+}
+
+// NewJWTAuthenticator creates a new JWTAuthenticator and loads its signing keys.
+func NewJWTAuthenticator(config JWTConfig) (*JWTAuthenticator, error) {
+	if config.Issuer == "" {
+		return nil, fmt.Errorf("OIDC issuer is required")
+	}
+	if config.JWKSFile != "" && config.JWKSURL != "" {
+		return nil, fmt.Errorf("only one of the JWKS file and JWKS URL can be set")
+	}
+	if config.UsernameClaim == "" {
+		config.UsernameClaim = "sub"
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+
+	keys := &keySet{httpClient: &http.Client{Timeout: 10 * time.Second}}
+	switch {
+	case config.JWKSFile != "":
+		keys.fetch = func() ([]byte, error) { return os.ReadFile(config.JWKSFile) }
+	case config.JWKSURL != "":
+		keys.fetch = func() ([]byte, error) { return keys.get(config.JWKSURL) }
+	default:
+		keys.fetch = func() ([]byte, error) {
+			jwksURL, err := keys.discoverJWKSURL(config.Issuer)
+			if err != nil {
+				return nil, err
+			}
+			return keys.get(jwksURL)
+		}
+	}
+
+	if err := keys.refresh(); err != nil {
+		return nil, fmt.Errorf("failed to load JWKS: %w", err)
+	}
+
+	return &JWTAuthenticator{config: config, keys: keys}, nil
+}
+
+// Authenticate implements Authenticator.
+func (a *JWTAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	token, err := bearerToken(r)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := a.verify(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT: %w", err)
+	}
+
+	username, _ := claims[a.config.UsernameClaim].(string)
+	if username == "" {
+		return nil, fmt.Errorf("invalid JWT: claim %q is missing", a.config.UsernameClaim)
+	}
+
+	identity := &Identity{Username: username}
+	if sub, ok := claims["sub"].(string); ok {
+		identity.UID = sub
+	}
+	switch groups := claims[a.config.GroupsClaim].(type) {
+	case string:
+		identity.Groups = []string{groups}
+	case []interface{}:
+		for _, group := range groups {
+			if name, ok := group.(string); ok {
+				identity.Groups = append(identity.Groups, name)
+			}
+		}
+	}
+	return identity, nil
+}
+
+// verify checks the token signature and standard claims and returns its claims.
+func (a *JWTAuthenticator) verify(token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed header: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed signature: %w", err)
+	}
+
+	key, err := a.keys.key(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if key.alg != "" && key.alg != header.Alg {
+		return nil, fmt.Errorf("key %q cannot be used with algorithm %q", header.Kid, header.Alg)
+	}
+	if err := verifySignature(header.Alg, key.key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed claims: %w", err)
+	}
+
+	if iss, _ := claims["iss"].(string); iss != a.config.Issuer {
+		return nil, fmt.Errorf("unexpected issuer %q", iss)
+	}
+	if a.config.Audience != "" && !hasAudience(claims["aud"], a.config.Audience) {
+		return nil, fmt.Errorf("token is not intended for audience %q", a.config.Audience)
+	}
+
+	now := time.Now()
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("token has no expiry")
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(clockSkew)) {
+		return nil, fmt.Errorf("token has expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(clockSkew).Before(time.Unix(int64(nbf), 0)) {
+		return nil, fmt.Errorf("token is not valid yet")
+	}
+
+	return claims, nil
+}
+
+// decodeSegment decodes a base64url-encoded JSON segment of a JWT.
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// hasAudience reports whether the aud claim, a string or a list of strings, contains audience.
+func hasAudience(aud interface{}, audience string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == audience
+	case []interface{}:
+		for _, value := range aud {
+			if value == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ecCurves are the curves the ES* algorithms are defined for.
+var ecCurves = map[string]elliptic.Curve{
+	"ES256": elliptic.P256(),
+	"ES384": elliptic.P384(),
+	"ES512": elliptic.P521(),
+}
+
+// verifySignature verifies a JWS signature for the RS*, PS* and ES* algorithms.
+func verifySignature(alg string, key crypto.PublicKey, signingInput, signature []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "PS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "PS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "PS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+	hasher := hash.New()
+	hasher.Write(signingInput)
+	digest := hasher.Sum(nil)
+
+	switch alg[:2] {
+	case "RS", "PS":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type does not match algorithm %q", alg)
+		}
+		if alg[:2] == "PS" {
+			return rsa.VerifyPSS(rsaKey, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		return rsa.VerifyPKCS1v15(rsaKey, hash, digest, signature)
+	default:
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || ecKey.Curve != ecCurves[alg] {
+			return fmt.Errorf("key type does not match algorithm %q", alg)
+		}
+		size := (ecKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("invalid signature length")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(ecKey, digest, r, s) {
+			return fmt.Errorf("signature verification failed")
+		}
+		return nil
+	}
+}
+
+// signingKey is a verification key of a JWKS.
+type signingKey struct {
+	key crypto.PublicKey
+	// alg restricts the key to a single algorithm, if set.
+	alg string
+}
+
+// keySet holds the verification keys of a JWKS and refreshes them as needed.
+type keySet struct {
+	fetch      func() ([]byte, error)
+	httpClient *http.Client
+
+	// refreshLock serializes refreshes, so that concurrent requests with an
+	// unknown key ID fetch the JWKS once.
+	refreshLock sync.Mutex
+
+	lock        sync.RWMutex
+	keys        map[string]*signingKey
+	lastRefresh time.Time
+}
+
+// key returns the key with the given ID, refreshing the set when the key is
+// unknown or the set is stale. An empty kid matches a set with a single key.
+func (k *keySet) key(kid string) (*signingKey, error) {
+	k.lock.RLock()
+	key, found := k.lookup(kid)
+	stale := time.Since(k.lastRefresh) > jwksRefreshInterval
+	canRefresh := time.Since(k.lastRefresh) > jwksMinRefreshInterval
+	k.lock.RUnlock()
+
+	if (found && !stale) || (!found && !canRefresh) {
+		if !found {
+			return nil, fmt.Errorf("no signing key found for key ID %q", kid)
+		}
+		return key, nil
+	}
+
+	if err := k.refreshAtMostOnce(); err != nil {
+		if found {
+			// Keep using the cached key if the provider is temporarily unavailable
+			return key, nil
+		}
+		return nil, fmt.Errorf("failed to refresh JWKS: %w", err)
+	}
+
+	k.lock.RLock()
+	defer k.lock.RUnlock()
+	if key, found = k.lookup(kid); !found {
+		return nil, fmt.Errorf("no signing key found for key ID %q", kid)
+	}
+	return key, nil
+}
+
+// lookup finds a key by ID. The caller must hold the lock.
+func (k *keySet) lookup(kid string) (*signingKey, bool) {
+	if kid == "" && len(k.keys) == 1 {
+		for _, key := range k.keys {
+			return key, true
+		}
+	}
+	key, found := k.keys[kid]
+	return key, found
+}
+
+// refreshAtMostOnce refreshes the key set unless another request did within
+// jwksMinRefreshInterval while this one waited for its turn.
+func (k *keySet) refreshAtMostOnce() error {
+	k.refreshLock.Lock()
+	defer k.refreshLock.Unlock()
+
+	k.lock.RLock()
+	recent := time.Since(k.lastRefresh) <= jwksMinRefreshInterval
+	k.lock.RUnlock()
+	if recent {
+		return nil
+	}
+	return k.refresh()
+}
+
+// refresh re-fetches and parses the key set.
+func (k *keySet) refresh() error {
+	data, err := k.fetch()
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.lastRefresh = time.Now()
+	if err != nil {
+		return err
+	}
+
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return err
+	}
+	k.keys = keys
+	return nil
+}
+
+// get fetches a URL and returns the response body.
+func (k *keySet) get(url string) ([]byte, error) {
+	resp, err := k.httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status fetching %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// discoverJWKSURL reads the jwks_uri from the issuer's OIDC discovery document.
+func (k *keySet) discoverJWKSURL(issuer string) (string, error) {
+	data, err := k.get(strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration")
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch OIDC discovery document: %w", err)
+	}
+
+	var discovery struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := json.Unmarshal(data, &discovery); err != nil {
+		return "", fmt.Errorf("failed to parse OIDC discovery document: %w", err)
+	}
+	if discovery.JWKSURI == "" {
+		return "", fmt.Errorf("OIDC discovery document has no jwks_uri")
+	}
+	return discovery.JWKSURI, nil
+}
+
+// parseJWKS parses the RSA and EC signing keys of a JSON Web Key Set.
+func parseJWKS(data []byte) (map[string]*signingKey, error) {
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			Alg string `json:"alg"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]*signingKey)
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		switch jwk.Kty {
+		case "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(jwk.N)
+			e, errE := base64.RawURLEncoding.DecodeString(jwk.E)
+			if errN != nil || errE != nil || len(e) == 0 || len(e) > 4 {
+				return nil, fmt.Errorf("invalid RSA key %q", jwk.Kid)
+			}
+			keys[jwk.Kid] = &signingKey{
+				key: &rsa.PublicKey{
+					N: new(big.Int).SetBytes(n),
+					E: int(new(big.Int).SetBytes(e).Int64()),
+				},
+				alg: jwk.Alg,
+			}
+		case "EC":
+			var curve elliptic.Curve
+			switch jwk.Crv {
+			case "P-256":
+				curve = elliptic.P256()
+			case "P-384":
+				curve = elliptic.P384()
+			case "P-521":
+				curve = elliptic.P521()
+			default:
+				continue
+			}
+			x, errX := base64.RawURLEncoding.DecodeString(jwk.X)
+			y, errY := base64.RawURLEncoding.DecodeString(jwk.Y)
+			size := (curve.Params().BitSize + 7) / 8
+			if errX != nil || errY != nil || len(x) != size || len(y) != size {
+				return nil, fmt.Errorf("invalid EC key %q", jwk.Kid)
+			}
+			key := &ecdsa.PublicKey{
+				Curve: curve,
+				X:     new(big.Int).SetBytes(x),
+				Y:     new(big.Int).SetBytes(y),
+			}
+			if !curve.IsOnCurve(key.X, key.Y) {
+				return nil, fmt.Errorf("invalid EC key %q: point is not on curve %s", jwk.Kid, jwk.Crv)
+			}
+			keys[jwk.Kid] = &signingKey{key: key, alg: jwk.Alg}
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWKS contains no usable signing keys")
+	}
+	return keys, nil
+}
@@ -0,0 +1,574 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testIssuer = "https://issuer.example.com"
+
+// testKey is a private key with the JWK of its public key.
+type testKey struct {
+	kid     string
+	private crypto.Signer
+	// jwkAlg is published as the alg of the JWK, if set.
+	jwkAlg string
+}
+
+func newRSAKey(t *testing.T, kid string) *testKey {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testKey{kid: kid, private: private}
+}
+
+func newECKey(t *testing.T, kid string, curve elliptic.Curve) *testKey {
+	t.Helper()
+	private, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testKey{kid: kid, private: private}
+}
+
+// jwk returns the JSON Web Key of the public key.
+func (k *testKey) jwk() map[string]interface{} {
+	encode := base64.RawURLEncoding.EncodeToString
+	jwk := map[string]interface{}{"kid": k.kid, "use": "sig"}
+	if k.jwkAlg != "" {
+		jwk["alg"] = k.jwkAlg
+	}
+	switch public := k.private.Public().(type) {
+	case *rsa.PublicKey:
+		jwk["kty"] = "RSA"
+		jwk["n"] = encode(public.N.Bytes())
+		jwk["e"] = encode(big.NewInt(int64(public.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (public.Curve.Params().BitSize + 7) / 8
+		jwk["kty"] = "EC"
+		jwk["crv"] = public.Curve.Params().Name
+		jwk["x"] = encode(public.X.FillBytes(make([]byte, size)))
+		jwk["y"] = encode(public.Y.FillBytes(make([]byte, size)))
+	}
+	return jwk
+}
+
+func jwksJSON(t *testing.T, keys ...*testKey) []byte {
+	t.Helper()
+	var jwks []map[string]interface{}
+	for _, key := range keys {
+		jwks = append(jwks, key.jwk())
+	}
+	data, err := json.Marshal(map[string]interface{}{"keys": jwks})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// newFileAuthenticator returns an authenticator reading the JWKS of keys from a file.
+func newFileAuthenticator(t *testing.T, audience string, keys ...*testKey) *JWTAuthenticator {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(file, jwksJSON(t, keys...), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	authenticator, err := NewJWTAuthenticator(JWTConfig{Issuer: testIssuer, Audience: audience, JWKSFile: file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return authenticator
+}
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// signToken signs claims with key, using the algorithm and key ID in header.
+func signToken(t *testing.T, header, claims map[string]interface{}, key *testKey) string {
+	t.Helper()
+	input := encodeSegment(t, header) + "." + encodeSegment(t, claims)
+	alg, _ := header["alg"].(string)
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature(t, alg, key, []byte(input)))
+}
+
+func signature(t *testing.T, alg string, key *testKey, input []byte) []byte {
+	t.Helper()
+	if alg == "none" {
+		return nil
+	}
+
+	hash := map[string]crypto.Hash{"256": crypto.SHA256, "384": crypto.SHA384, "512": crypto.SHA512}[alg[2:]]
+	hasher := hash.New()
+	hasher.Write(input)
+	digest := hasher.Sum(nil)
+
+	var sig []byte
+	var err error
+	switch private := key.private.(type) {
+	case *rsa.PrivateKey:
+		if strings.HasPrefix(alg, "PS") {
+			sig, err = rsa.SignPSS(rand.Reader, private, hash, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		} else {
+			sig, err = rsa.SignPKCS1v15(rand.Reader, private, hash, digest)
+		}
+	case *ecdsa.PrivateKey:
+		var r, s *big.Int
+		if r, s, err = ecdsa.Sign(rand.Reader, private, digest); err == nil {
+			size := (private.Curve.Params().BitSize + 7) / 8
+			sig = append(r.FillBytes(make([]byte, size)), s.FillBytes(make([]byte, size))...)
+		}
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"iss":    testIssuer,
+		"sub":    "alice",
+		"aud":    "k8s-mcp",
+		"exp":    time.Now().Add(time.Hour).Unix(),
+		"groups": []string{"dev"},
+	}
+}
+
+func TestJWTVerify(t *testing.T) {
+	rsaKey := newRSAKey(t, "rsa")
+	p256 := newECKey(t, "p256", elliptic.P256())
+	p384 := newECKey(t, "p384", elliptic.P384())
+	p521 := newECKey(t, "p521", elliptic.P521())
+	restricted := newRSAKey(t, "restricted")
+	restricted.jwkAlg = "RS256"
+	authenticator := newFileAuthenticator(t, "k8s-mcp", rsaKey, p256, p384, p521, restricted)
+
+	now := time.Now()
+	withClaims := func(changes map[string]interface{}) map[string]interface{} {
+		claims := validClaims()
+		for name, value := range changes {
+			if value == nil {
+				delete(claims, name)
+			} else {
+				claims[name] = value
+			}
+		}
+		return claims
+	}
+	header := func(alg, kid string) map[string]interface{} {
+		return map[string]interface{}{"alg": alg, "kid": kid, "typ": "JWT"}
+	}
+
+	tests := []struct {
+		name    string
+		token   func() string
+		wantErr string
+	}{
+		// Algorithms
+		{name: "RS256", token: func() string { return signToken(t, header("RS256", "rsa"), validClaims(), rsaKey) }},
+		{name: "PS256", token: func() string { return signToken(t, header("PS256", "rsa"), validClaims(), rsaKey) }},
+		{name: "RS512", token: func() string { return signToken(t, header("RS512", "rsa"), validClaims(), rsaKey) }},
+		{name: "ES256", token: func() string { return signToken(t, header("ES256", "p256"), validClaims(), p256) }},
+		{name: "ES384", token: func() string { return signToken(t, header("ES384", "p384"), validClaims(), p384) }},
+		{name: "ES512 with P-521", token: func() string { return signToken(t, header("ES512", "p521"), validClaims(), p521) }},
+		{
+			name:    "alg none",
+			token:   func() string { return signToken(t, header("none", "rsa"), validClaims(), rsaKey) },
+			wantErr: `unsupported signing algorithm "none"`,
+		},
+		{
+			name: "HS256 signed with the RSA public key",
+			token: func() string {
+				public, err := x509.MarshalPKIXPublicKey(rsaKey.private.Public())
+				if err != nil {
+					t.Fatal(err)
+				}
+				input := encodeSegment(t, header("HS256", "rsa")) + "." + encodeSegment(t, validClaims())
+				mac := hmac.New(sha256.New, public)
+				mac.Write([]byte(input))
+				return input + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+			},
+			wantErr: `unsupported signing algorithm "HS256"`,
+		},
+		{
+			name:    "RSA algorithm with an EC key",
+			token:   func() string { return signToken(t, header("RS256", "p256"), validClaims(), rsaKey) },
+			wantErr: `key type does not match algorithm "RS256"`,
+		},
+		{
+			name:    "EC algorithm with an RSA key",
+			token:   func() string { return signToken(t, header("ES256", "rsa"), validClaims(), p256) },
+			wantErr: `key type does not match algorithm "ES256"`,
+		},
+		{
+			name:    "ES256 with a P-384 key",
+			token:   func() string { return signToken(t, header("ES256", "p384"), validClaims(), p384) },
+			wantErr: `key type does not match algorithm "ES256"`,
+		},
+		{
+			name:    "algorithm other than the key's",
+			token:   func() string { return signToken(t, header("PS256", "restricted"), validClaims(), restricted) },
+			wantErr: `key "restricted" cannot be used with algorithm "PS256"`,
+		},
+		{
+			name: "tampered claims",
+			token: func() string {
+				token := signToken(t, header("RS256", "rsa"), validClaims(), rsaKey)
+				parts := strings.Split(token, ".")
+				parts[1] = encodeSegment(t, withClaims(map[string]interface{}{"sub": "admin"}))
+				return strings.Join(parts, ".")
+			},
+			wantErr: "verification error",
+		},
+
+		// ES signature lengths
+		{
+			name: "ES256 signature in ASN.1 DER",
+			token: func() string {
+				input := encodeSegment(t, header("ES256", "p256")) + "." + encodeSegment(t, validClaims())
+				digest := sha256.Sum256([]byte(input))
+				der, err := ecdsa.SignASN1(rand.Reader, p256.private.(*ecdsa.PrivateKey), digest[:])
+				if err != nil {
+					t.Fatal(err)
+				}
+				return input + "." + base64.RawURLEncoding.EncodeToString(der)
+			},
+			wantErr: "invalid signature length",
+		},
+		{
+			name: "ES256 signature truncated",
+			token: func() string {
+				token := signToken(t, header("ES256", "p256"), validClaims(), p256)
+				parts := strings.Split(token, ".")
+				sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+				parts[2] = base64.RawURLEncoding.EncodeToString(sig[:63])
+				return strings.Join(parts, ".")
+			},
+			wantErr: "invalid signature length",
+		},
+		{
+			name: "ES512 signature of a P-256 size",
+			token: func() string {
+				token := signToken(t, header("ES256", "p256"), validClaims(), p256)
+				parts := strings.Split(token, ".")
+				parts[0] = encodeSegment(t, header("ES512", "p521"))
+				return strings.Join(parts, ".")
+			},
+			wantErr: "invalid signature length",
+		},
+
+		// exp and nbf
+		{
+			name: "expired within the leeway",
+			token: func() string {
+				return signToken(t, header("RS256", "rsa"), withClaims(map[string]interface{}{"exp": now.Add(-30 * time.Second).Unix()}), rsaKey)
+			},
+		},
+		{
+			name: "expired beyond the leeway",
+			token: func() string {
+				return signToken(t, header("RS256", "rsa"), withClaims(map[string]interface{}{"exp": now.Add(-2 * time.Minute).Unix()}), rsaKey)
+			},
+			wantErr: "token has expired",
+		},
+		{
+			name: "no exp",
+			token: func() string {
+				return signToken(t, header("RS256", "rsa"), withClaims(map[string]interface{}{"exp": nil}), rsaKey)
+			},
+			wantErr: "token has no expiry",
+		},
+		{
+			name: "exp as a string",
+			token: func() string {
+				return signToken(t, header("RS256", "rsa"), withClaims(map[string]interface{}{"exp": "4102444800"}), rsaKey)
+			},
+			wantErr: "token has no expiry",
+		},
+		{
+			name: "not valid yet within the leeway",
+			token: func() string {
+				return signToken(t, header("RS256", "rsa"), withClaims(map[string]interface{}{"nbf": now.Add(30 * time.Second).Unix()}), rsaKey)
+			},
+		},
+		{
+			name: "not valid yet beyond the leeway",
+			token: func() string {
+				return signToken(t, header("RS256", "rsa"), withClaims(map[string]interface{}{"nbf": now.Add(2 * time.Minute).Unix()}), rsaKey)
+			},
+			wantErr: "token is not valid yet",
+		},
+
+		// iss and aud
+		{
+			name: "other issuer",
+			token: func() string {
+				return signToken(t, header("RS256", "rsa"), withClaims(map[string]interface{}{"iss": "https://evil.example.com"}), rsaKey)
+			},
+			wantErr: "unexpected issuer",
+		},
+		{
+			name: "aud as a list",
+			token: func() string {
+				return signToken(t, header("RS256", "rsa"), withClaims(map[string]interface{}{"aud": []string{"other", "k8s-mcp"}}), rsaKey)
+			},
+		},
+		{
+			name: "aud as a list without the audience",
+			token: func() string {
+				return signToken(t, header("RS256", "rsa"), withClaims(map[string]interface{}{"aud": []string{"other"}}), rsaKey)
+			},
+			wantErr: "not intended for audience",
+		},
+		{
+			name: "aud as another string",
+			token: func() string {
+				return signToken(t, header("RS256", "rsa"), withClaims(map[string]interface{}{"aud": "other"}), rsaKey)
+			},
+			wantErr: "not intended for audience",
+		},
+		{
+			name: "no aud",
+			token: func() string {
+				return signToken(t, header("RS256", "rsa"), withClaims(map[string]interface{}{"aud": nil}), rsaKey)
+			},
+			wantErr: "not intended for audience",
+		},
+
+		// Malformed tokens
+		{name: "two segments", token: func() string { return "a.b" }, wantErr: "malformed token"},
+		{name: "header not base64url", token: func() string { return "!!.e30.e30" }, wantErr: "malformed header"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := authenticator.verify(tt.token())
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("verify failed: %v", err)
+			case tt.wantErr == "" && claims["sub"] != "alice":
+				t.Fatalf("verify returned claims %v", claims)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("verify error = %v; want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJWTAudienceNotConfigured(t *testing.T) {
+	key := newRSAKey(t, "rsa")
+	authenticator := newFileAuthenticator(t, "", key)
+
+	claims := validClaims()
+	delete(claims, "aud")
+	if _, err := authenticator.verify(signToken(t, map[string]interface{}{"alg": "RS256", "kid": "rsa"}, claims, key)); err != nil {
+		t.Fatalf("verify failed without a configured audience: %v", err)
+	}
+}
+
+func TestJWTAuthenticate(t *testing.T) {
+	key := newECKey(t, "p256", elliptic.P256())
+	authenticator := newFileAuthenticator(t, "k8s-mcp", key)
+
+	request := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	request.Header.Set("Authorization", "Bearer "+signToken(t, map[string]interface{}{"alg": "ES256", "kid": "p256"}, validClaims(), key))
+	identity, err := authenticator.Authenticate(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.Username != "alice" || identity.UID != "alice" || len(identity.Groups) != 1 || identity.Groups[0] != "dev" {
+		t.Errorf("identity = %+v", identity)
+	}
+}
+
+// jwksServer serves the JWKS of keys, which can be replaced, and counts the fetches.
+type jwksServer struct {
+	lock    sync.Mutex
+	jwks    []byte
+	fetches atomic.Int32
+}
+
+func newJWKSServer(t *testing.T, keys ...*testKey) (*jwksServer, *httptest.Server) {
+	jwks := &jwksServer{jwks: jwksJSON(t, keys...)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		jwks.fetches.Add(1)
+		jwks.lock.Lock()
+		defer jwks.lock.Unlock()
+		w.Write(jwks.jwks)
+	}))
+	t.Cleanup(server.Close)
+	return jwks, server
+}
+
+func (s *jwksServer) publish(t *testing.T, keys ...*testKey) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.jwks = jwksJSON(t, keys...)
+}
+
+func TestJWTKeyIDs(t *testing.T) {
+	first := newECKey(t, "first", elliptic.P256())
+	second := newECKey(t, "second", elliptic.P256())
+	rotated := newECKey(t, "rotated", elliptic.P256())
+
+	jwks, server := newJWKSServer(t, first)
+	authenticator, err := NewJWTAuthenticator(JWTConfig{Issuer: testIssuer, JWKSURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := func(kid string, key *testKey) string {
+		header := map[string]interface{}{"alg": "ES256"}
+		if kid != "" {
+			header["kid"] = kid
+		}
+		return signToken(t, header, validClaims(), key)
+	}
+	expectFetches := func(want int32) {
+		t.Helper()
+		if got := jwks.fetches.Load(); got != want {
+			t.Fatalf("JWKS fetched %d times; want %d", got, want)
+		}
+	}
+	expectFetches(1)
+
+	// A missing kid matches a set with a single key
+	if _, err := authenticator.verify(token("", first)); err != nil {
+		t.Fatalf("token without kid rejected: %v", err)
+	}
+
+	// Unknown key IDs refresh the set at most once per jwksMinRefreshInterval
+	jwks.publish(t, first, rotated)
+	if _, err := authenticator.verify(token("rotated", rotated)); err == nil || !strings.Contains(err.Error(), `no signing key found for key ID "rotated"`) {
+		t.Fatalf("verify error = %v; want the key not to be found before the refresh interval", err)
+	}
+	if _, err := authenticator.verify(token("unknown", first)); err == nil {
+		t.Fatal("token with an unknown kid accepted")
+	}
+	expectFetches(1)
+
+	authenticator.keys.lastRefresh = time.Now().Add(-2 * jwksMinRefreshInterval)
+	if _, err := authenticator.verify(token("rotated", rotated)); err != nil {
+		t.Fatalf("token signed with the rotated key rejected: %v", err)
+	}
+	expectFetches(2)
+
+	// A wrong kid does not fall back to another key
+	if _, err := authenticator.verify(token("first", second)); err == nil || !strings.Contains(err.Error(), "signature verification failed") {
+		t.Fatalf("verify error = %v; want the signature verification to fail", err)
+	}
+	// With several keys, a missing kid matches none of them
+	if _, err := authenticator.verify(token("", first)); err == nil || !strings.Contains(err.Error(), "no signing key found") {
+		t.Fatalf("verify error = %v; want no key to be found", err)
+	}
+	expectFetches(2)
+
+	// Concurrent requests with unknown key IDs fetch the set once
+	authenticator.keys.lastRefresh = time.Now().Add(-2 * jwksMinRefreshInterval)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			authenticator.verify(token("unknown", first))
+		}()
+	}
+	wg.Wait()
+	expectFetches(3)
+}
+
+func TestJWTKeySetStale(t *testing.T) {
+	key := newECKey(t, "key", elliptic.P256())
+	jwks, server := newJWKSServer(t, key)
+	authenticator, err := NewJWTAuthenticator(JWTConfig{Issuer: testIssuer, JWKSURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := signToken(t, map[string]interface{}{"alg": "ES256", "kid": "key"}, validClaims(), key)
+
+	// A stale set is refreshed, and its keys are kept if the refresh fails
+	authenticator.keys.lastRefresh = time.Now().Add(-2 * jwksRefreshInterval)
+	server.Close()
+	if _, err := authenticator.verify(token); err != nil {
+		t.Fatalf("cached key not used while the JWKS is unavailable: %v", err)
+	}
+	if got := jwks.fetches.Load(); got != 1 {
+		t.Fatalf("JWKS fetched %d times; want 1", got)
+	}
+}
+
+func TestParseJWKS(t *testing.T) {
+	encode := base64.RawURLEncoding.EncodeToString
+	valid := newECKey(t, "valid", elliptic.P256()).jwk()
+	offCurve := newECKey(t, "off-curve", elliptic.P256()).jwk()
+	offCurve["y"] = encode(make([]byte, 32))
+	shortX := newECKey(t, "short", elliptic.P256()).jwk()
+	shortX["x"] = encode(make([]byte, 31))
+	encryption := newECKey(t, "enc", elliptic.P256()).jwk()
+	encryption["use"] = "enc"
+
+	tests := []struct {
+		name     string
+		keys     []map[string]interface{}
+		wantKeys []string
+		wantErr  string
+	}{
+		{name: "EC key", keys: []map[string]interface{}{valid}, wantKeys: []string{"valid"}},
+		{name: "point not on the curve", keys: []map[string]interface{}{offCurve}, wantErr: "not on curve"},
+		{name: "coordinate of the wrong size", keys: []map[string]interface{}{shortX}, wantErr: "invalid EC key"},
+		{name: "encryption keys are skipped", keys: []map[string]interface{}{valid, encryption}, wantKeys: []string{"valid"}},
+		{name: "no signing keys", keys: []map[string]interface{}{encryption}, wantErr: "no usable signing keys"},
+		{
+			name:    "RSA exponent too large",
+			keys:    []map[string]interface{}{{"kty": "RSA", "kid": "rsa", "n": encode([]byte{1, 2, 3}), "e": encode([]byte{1, 0, 0, 0, 0, 1})}},
+			wantErr: "invalid RSA key",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := json.Marshal(map[string]interface{}{"keys": tt.keys})
+			keys, err := parseJWKS(data)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseJWKS error = %v; want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(keys) != len(tt.wantKeys) {
+				t.Fatalf("parseJWKS returned %d keys; want %v", len(keys), tt.wantKeys)
+			}
+			for _, kid := range tt.wantKeys {
+				if keys[kid] == nil {
+					t.Errorf("key %q missing", kid)
+				}
+			}
+		})
+	}
+}
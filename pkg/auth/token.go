@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// TokenFileAuthenticator authenticates requests with static bearer tokens.
+type TokenFileAuthenticator struct {
+	tokens map[string]*Identity
+}
+
+// NewTokenFileAuthenticator loads a static token file.
+// The file uses the same CSV format as the Kubernetes API server's
+// --token-auth-file: token,user,uid,"group1,group2". The uid and groups
+// columns are optional, and lines starting with # are ignored.
+func NewTokenFileAuthenticator(path string) (*TokenFileAuthenticator, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open token file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	tokens := make(map[string]*Identity)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse token file %s: %w", path, err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		if len(record) < 2 || record[0] == "" || record[1] == "" {
+			return nil, fmt.Errorf("token file %s line %d: token and user are required", path, line)
+		}
+		if _, exists := tokens[record[0]]; exists {
+			return nil, fmt.Errorf("token file %s line %d: duplicate token", path, line)
+		}
+
+		identity := &Identity{Username: record[1]}
+		if len(record) >= 3 {
+			identity.UID = record[2]
+		}
+		if len(record) >= 4 && record[3] != "" {
+			for _, group := range strings.Split(record[3], ",") {
+				if group = strings.TrimSpace(group); group != "" {
+					identity.Groups = append(identity.Groups, group)
+				}
+			}
+		}
+		tokens[record[0]] = identity
+	}
+
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("token file %s contains no tokens", path)
+	}
+	return &TokenFileAuthenticator{tokens: tokens}, nil
+}
+
+// Authenticate implements Authenticator.
+func (a *TokenFileAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	token, err := bearerToken(r)
+	if err != nil {
+		return nil, err
+	}
+	identity, ok := a.tokens[token]
+	if !ok {
+		return nil, fmt.Errorf("invalid bearer token")
+	}
+	return identity, nil
+}
@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"net/http"
+)
+
+// ClientCertAuthenticator authenticates requests by their verified TLS client
+// certificate. Like the Kubernetes API server, it maps the certificate's
+// Common Name to the username and its Organizations to groups.
+type ClientCertAuthenticator struct{}
+
+// NewClientCertAuthenticator creates a new ClientCertAuthenticator.
+func NewClientCertAuthenticator() *ClientCertAuthenticator {
+	return &ClientCertAuthenticator{}
+}
+
+// Authenticate implements Authenticator.
+func (a *ClientCertAuthenticator) Authenticate(r *http.Request) (*Identity, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil, ErrNoCredentials
+	}
+
+	subject := r.TLS.VerifiedChains[0][0].Subject
+	if subject.CommonName == "" {
+		return nil, ErrNoCredentials
+	}
+	return &Identity{
+		Username: subject.CommonName,
+		Groups:   subject.Organization,
+	}, nil
+}
@@ -0,0 +1,262 @@
+// Package cluster provides a registry of Kubernetes and Helm clients keyed by
+// kubeconfig context, so a single server can operate on several clusters.
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/auth"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/helm"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/kubeconfig"
+
+	"k8s.io/client-go/rest"
+)
+
+// maxImpersonatedClients bounds the number of cached per-caller client sets.
+const maxImpersonatedClients = 256
+
+// ContextInfo describes a single context from the kubeconfig.
+type ContextInfo struct {
+	Name      string `json:"name"`
+	Cluster   string `json:"cluster"`
+	User      string `json:"user"`
+	Namespace string `json:"namespace,omitempty"`
+	Current   bool   `json:"current"`
+}
+
+// clientSet holds the clients for one context. Both clients are built from the
+// same rest.Config so they always talk to the same cluster as the same user.
+type clientSet struct {
+	k8s  *k8s.Client
+	helm *helm.Client
+}
+
+// Registry lazily creates and caches clients per kubeconfig context.
+// The kubeconfig is re-read on every lookup so that contexts added after
+// startup become available without a restart; clients themselves are cached.
+//
+// In impersonation mode every client impersonates the authenticated MCP
+// caller found in the request context, so Kubernetes RBAC decides what each
+// caller may do and the API server audit log records the real user.
+type Registry struct {
+	loader       *kubeconfig.Loader
+	impersonate  bool
+	discoveryTTL time.Duration
+	configs      map[string]*rest.Config
+	clients      map[string]*clientSet
+	lock         sync.Mutex
+}
+
+// NewRegistry creates a new client registry that resolves contexts with loader.
+// If impersonate is true, clients impersonate the caller attached to the
+// context with auth.WithIdentity, and calls without a caller are rejected.
+// Kubernetes clients cache API discovery for discoveryTTL (see k8s.Client.SetDiscoveryTTL).
+func NewRegistry(loader *kubeconfig.Loader, impersonate bool, discoveryTTL time.Duration) *Registry {
+	return &Registry{
+		loader:       loader,
+		impersonate:  impersonate,
+		discoveryTTL: discoveryTTL,
+		configs:      make(map[string]*rest.Config),
+		clients:      make(map[string]*clientSet),
+	}
+}
+
+// K8s returns the Kubernetes client for the given context.
+// An empty context name selects the default context (see CurrentContext).
+func (r *Registry) K8s(ctx context.Context, contextName string) (*k8s.Client, error) {
+	set, err := r.clientSetFor(ctx, contextName)
+	if err != nil {
+		return nil, err
+	}
+	return set.k8s, nil
+}
+
+// Helm returns the Helm client for the given context.
+// An empty context name selects the default context (see CurrentContext).
+func (r *Registry) Helm(ctx context.Context, contextName string) (*helm.Client, error) {
+	set, err := r.clientSetFor(ctx, contextName)
+	if err != nil {
+		return nil, err
+	}
+	return set.helm, nil
+}
+
+// Validate checks that the REST config of the default context can be built.
+func (r *Registry) Validate() error {
+	rawConfig, err := r.loader.RawConfig()
+	if err != nil {
+		return err
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	_, err = r.restConfigFor(rawConfig.CurrentContext)
+	return err
+}
+
+// Contexts returns all contexts defined in the kubeconfig, sorted by name.
+func (r *Registry) Contexts() ([]ContextInfo, error) {
+	rawConfig, err := r.loader.RawConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	contexts := make([]ContextInfo, 0, len(rawConfig.Contexts))
+	for name, kubeContext := range rawConfig.Contexts {
+		contexts = append(contexts, ContextInfo{
+			Name:      name,
+			Cluster:   kubeContext.Cluster,
+			User:      kubeContext.AuthInfo,
+			Namespace: kubeContext.Namespace,
+			Current:   name == rawConfig.CurrentContext,
+		})
+	}
+	sort.Slice(contexts, func(i, j int) bool {
+		return contexts[i].Name < contexts[j].Name
+	})
+	return contexts, nil
+}
+
+// CurrentContext returns the context used when no context is requested: the
+// --context override if one was configured, otherwise the kubeconfig's current context.
+func (r *Registry) CurrentContext() (*ContextInfo, error) {
+	return r.Context("")
+}
+
+// Context returns the named context from the kubeconfig.
+// An empty name selects the default context (see CurrentContext).
+func (r *Registry) Context(contextName string) (*ContextInfo, error) {
+	rawConfig, err := r.loader.RawConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if contextName == "" {
+		contextName = rawConfig.CurrentContext
+	}
+	kubeContext, ok := rawConfig.Contexts[contextName]
+	if !ok {
+		return nil, fmt.Errorf("context %q not found in kubeconfig", contextName)
+	}
+	return &ContextInfo{
+		Name:      contextName,
+		Cluster:   kubeContext.Cluster,
+		User:      kubeContext.AuthInfo,
+		Namespace: kubeContext.Namespace,
+		Current:   contextName == rawConfig.CurrentContext,
+	}, nil
+}
+
+// InvalidateDiscovery drops the cached API discovery of every Kubernetes client
+// of a context, including the clients of impersonated callers.
+// An empty context name selects the default context (see CurrentContext).
+func (r *Registry) InvalidateDiscovery(contextName string) error {
+	info, err := r.Context(contextName)
+	if err != nil {
+		return err
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for cacheKey, set := range r.clients {
+		if cacheKey == info.Name || strings.HasPrefix(cacheKey, info.Name+"\x00") {
+			set.k8s.InvalidateDiscovery()
+		}
+	}
+	return nil
+}
+
+// clientSetFor returns the cached clients for a context, creating them on first use.
+// In impersonation mode the clients are specific to the caller found in ctx.
+func (r *Registry) clientSetFor(ctx context.Context, contextName string) (*clientSet, error) {
+	rawConfig, err := r.loader.RawConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if contextName == "" {
+		contextName = rawConfig.CurrentContext
+	}
+	if _, ok := rawConfig.Contexts[contextName]; !ok {
+		return nil, fmt.Errorf("context %q not found in kubeconfig", contextName)
+	}
+
+	cacheKey := contextName
+	var identity *auth.Identity
+	if r.impersonate {
+		var ok bool
+		if identity, ok = auth.IdentityFromContext(ctx); !ok {
+			return nil, fmt.Errorf("impersonation is enabled but the request has no authenticated caller")
+		}
+		cacheKey = impersonationCacheKey(contextName, identity)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if set, exists := r.clients[cacheKey]; exists {
+		return set, nil
+	}
+
+	restConfig, err := r.restConfigFor(contextName)
+	if err != nil {
+		return nil, err
+	}
+
+	if identity != nil {
+		restConfig = rest.CopyConfig(restConfig)
+		restConfig.Impersonate = rest.ImpersonationConfig{
+			UserName: identity.Username,
+			Groups:   identity.Groups,
+		}
+
+		// Keep the cache bounded; evicted clients are simply re-created on demand
+		if len(r.clients) >= maxImpersonatedClients {
+			for key := range r.clients {
+				delete(r.clients, key)
+				break
+			}
+		}
+	}
+
+	k8sClient, err := k8s.NewClientForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	k8sClient.SetDiscoveryTTL(r.discoveryTTL)
+
+	helmClient, err := helm.NewClientForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	set := &clientSet{k8s: k8sClient, helm: helmClient}
+	r.clients[cacheKey] = set
+	return set, nil
+}
+
+// restConfigFor returns the cached REST config of a context, loading it on
+// first use. The caller must hold the lock.
+func (r *Registry) restConfigFor(contextName string) (*rest.Config, error) {
+	if restConfig, exists := r.configs[contextName]; exists {
+		return restConfig, nil
+	}
+
+	restConfig, err := r.loader.RESTConfig(contextName)
+	if err != nil {
+		return nil, err
+	}
+	r.configs[contextName] = restConfig
+	return restConfig, nil
+}
+
+// impersonationCacheKey identifies the clients of one caller in one context.
+func impersonationCacheKey(contextName string, identity *auth.Identity) string {
+	return strings.Join(append([]string{contextName, identity.Username}, identity.Groups...), "\x00")
+}
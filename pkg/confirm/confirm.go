@@ -0,0 +1,129 @@
+// Package confirm implements a two-phase confirmation flow for destructive
+// tools. The first call returns a short-lived token together with a summary
+// of the impact; the operation only runs when the same caller repeats the
+// call with exactly the same arguments and that token.
+package confirm
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/auth"
+)
+
+// TokenArgument is the tool argument carrying the confirmation token.
+const TokenArgument = "confirmationToken"
+
+// DefaultTTL is how long a confirmation token stays valid.
+const DefaultTTL = 5 * time.Minute
+
+// Store issues and redeems confirmation tokens. Tokens are kept in memory and
+// can be redeemed once.
+type Store struct {
+	ttl     time.Duration
+	lock    sync.Mutex
+	pending map[string]pending
+}
+
+// pending is an issued, not yet redeemed token.
+type pending struct {
+	binding string
+	expires time.Time
+}
+
+// NewStore creates a token store whose tokens expire after ttl.
+func NewStore(ttl time.Duration) *Store {
+	return &Store{
+		ttl:     ttl,
+		pending: make(map[string]pending),
+	}
+}
+
+// Issue returns a new token bound to the caller in ctx, the tool and its arguments.
+func (s *Store) Issue(ctx context.Context, tool string, args map[string]interface{}) (string, time.Time, error) {
+	binding, err := bindingFor(ctx, tool, args)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate confirmation token: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+	expires := time.Now().Add(s.ttl)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.removeExpired()
+	s.pending[token] = pending{binding: binding, expires: expires}
+	return token, expires, nil
+}
+
+// Redeem consumes the token found in args. It fails if the token is unknown,
+// expired, or was issued to another caller, tool or set of arguments.
+func (s *Store) Redeem(ctx context.Context, tool string, args map[string]interface{}) error {
+	token, _ := args[TokenArgument].(string)
+	binding, err := bindingFor(ctx, tool, args)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.removeExpired()
+
+	issued, ok := s.pending[token]
+	if !ok {
+		return fmt.Errorf("invalid or expired confirmation token; call %s without %s to get a new one", tool, TokenArgument)
+	}
+	if issued.binding != binding {
+		return fmt.Errorf("confirmation token was issued for a different caller or different arguments; repeat the original call exactly")
+	}
+	delete(s.pending, token)
+	return nil
+}
+
+// removeExpired drops expired tokens. The caller must hold the lock.
+func (s *Store) removeExpired() {
+	now := time.Now()
+	for token, issued := range s.pending {
+		if now.After(issued.expires) {
+			delete(s.pending, token)
+		}
+	}
+}
+
+// bindingFor hashes the caller, the tool and the arguments other than the token.
+// JSON encoding sorts map keys, so equal arguments always hash the same.
+func bindingFor(ctx context.Context, tool string, args map[string]interface{}) (string, error) {
+	bound := make(map[string]interface{}, len(args))
+	for key, value := range args {
+		if key != TokenArgument {
+			bound[key] = value
+		}
+	}
+
+	var caller *auth.Identity
+	if identity, ok := auth.IdentityFromContext(ctx); ok {
+		caller = identity
+	}
+
+	data, err := json.Marshal(struct {
+		Caller    *auth.Identity         `json:"caller"`
+		Tool      string                 `json:"tool"`
+		Arguments map[string]interface{} `json:"arguments"`
+	}{caller, tool, bound})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode confirmation binding: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -3,6 +3,7 @@ package helm
 import (
 	"context"
 	"fmt"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/kubeconfig"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/cli"
@@ -12,7 +13,6 @@ import (
 	"helm.sh/helm/v3/pkg/repo"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
 	"log"
 	"os"
 	"path/filepath"
@@ -26,36 +26,26 @@ type Client struct {
 }
 
 // NewClient creates a new Helm client
-func NewClient(kubeconfig string) (*Client, error) {
-	settings := cli.New()
-
-	if kubeconfig != "" {
-		settings.KubeConfig = kubeconfig
-	}
-
-	// Get Kubernetes REST config
-	var restConfig *rest.Config
-	var err error
-
-	if settings.KubeConfig != "" {
-		restConfig, err = clientcmd.BuildConfigFromFlags("", settings.KubeConfig)
-	} else {
-		// Try to use the default kubeconfig path first
-		if home := os.Getenv("HOME"); home != "" {
-			defaultKubeconfig := filepath.Join(home, ".kube", "config")
-			if _, err := os.Stat(defaultKubeconfig); err == nil {
-				restConfig, err = clientcmd.BuildConfigFromFlags("", defaultKubeconfig)
-			} else {
-				restConfig, err = rest.InClusterConfig()
-			}
-		} else {
-			restConfig, err = rest.InClusterConfig()
-		}
+// The REST config comes from the shared kubeconfig loader, so it resolves the
+// cluster exactly like the Kubernetes client does.
+func NewClient(options kubeconfig.Options) (*Client, error) {
+	loader, err := kubeconfig.NewLoader(options)
+	if err != nil {
+		return nil, err
 	}
+
+	restConfig, err := loader.RESTConfig("")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get Kubernetes config: %w", err)
 	}
 
+	return NewClientForConfig(restConfig)
+}
+
+// NewClientForConfig creates a new Helm client from an existing REST config.
+// Helm actions run against the cluster and credentials of restConfig rather
+// than re-reading the kubeconfig.
+func NewClientForConfig(restConfig *rest.Config) (*Client, error) {
 	// Create Kubernetes client
 	k8sClient, err := kubernetes.NewForConfig(restConfig)
 	if err != nil {
@@ -63,15 +53,18 @@ func NewClient(kubeconfig string) (*Client, error) {
 	}
 
 	return &Client{
-		settings:   settings,
+		settings:   cli.New(),
 		restConfig: restConfig,
 		k8sClient:  k8sClient,
 	}, nil
 }
 
-func (c *Client) InstallChart(ctx context.Context, namespace, releaseName, chartName, repoURL string, values map[string]interface{}) (*release.Release, error) {
+// InstallChart installs a chart as a new release.
+// With dryRun set, the chart is rendered and validated against the cluster,
+// and the release that would be installed is returned without changing anything.
+func (c *Client) InstallChart(ctx context.Context, namespace, releaseName, chartName, repoURL string, values map[string]interface{}, dryRun bool) (*release.Release, error) {
 	actionConfig := &action.Configuration{}
-	if err := actionConfig.Init(c.settings.RESTClientGetter(), namespace, os.Getenv("HELM_DRIVER"), log.Printf); err != nil {
+	if err := actionConfig.Init(newRESTClientGetter(c.restConfig, namespace), namespace, os.Getenv("HELM_DRIVER"), log.Printf); err != nil {
 		return nil, fmt.Errorf("failed to initialize action config: %w", err)
 	}
 
@@ -79,6 +72,10 @@ func (c *Client) InstallChart(ctx context.Context, namespace, releaseName, chart
 	client.Namespace = namespace
 	client.ReleaseName = releaseName
 	client.CreateNamespace = true
+	if dryRun {
+		client.DryRun = true
+		client.DryRunOption = "server"
+	}
 	cln, err := registry.NewClient(
 		registry.ClientOptDebug(true),
 		registry.ClientOptCredentialsFile(""),
@@ -119,9 +116,11 @@ func (c *Client) InstallChart(ctx context.Context, namespace, releaseName, chart
 	return release, nil
 }
 
-func (c *Client) UpgradeChart(ctx context.Context, namespace, releaseName, chartName string, values map[string]interface{}) (*release.Release, error) {
+// UpgradeChart upgrades an existing release.
+// With dryRun set, the upgraded release is rendered and returned without being applied.
+func (c *Client) UpgradeChart(ctx context.Context, namespace, releaseName, chartName string, values map[string]interface{}, dryRun bool) (*release.Release, error) {
 	actionConfig := &action.Configuration{}
-	if err := actionConfig.Init(c.settings.RESTClientGetter(), namespace, os.Getenv("HELM_DRIVER"), log.Printf); err != nil {
+	if err := actionConfig.Init(newRESTClientGetter(c.restConfig, namespace), namespace, os.Getenv("HELM_DRIVER"), log.Printf); err != nil {
 		return nil, fmt.Errorf("failed to initialize action config: %w", err)
 	}
 
@@ -137,6 +136,10 @@ func (c *Client) UpgradeChart(ctx context.Context, namespace, releaseName, chart
 
 	client := action.NewUpgrade(actionConfig)
 	client.Namespace = namespace
+	if dryRun {
+		client.DryRun = true
+		client.DryRunOption = "server"
+	}
 
 	if values == nil {
 		values = make(map[string]interface{})
@@ -161,25 +164,27 @@ func (c *Client) UpgradeChart(ctx context.Context, namespace, releaseName, chart
 	return release, nil
 }
 
-// UninstallChart uninstalls a Helm release
-func (c *Client) UninstallChart(ctx context.Context, namespace, releaseName string) error {
+// UninstallChart uninstalls a Helm release and returns the removed release.
+// With dryRun set, the release that would be removed is returned and nothing is deleted.
+func (c *Client) UninstallChart(ctx context.Context, namespace, releaseName string, dryRun bool) (*release.Release, error) {
 	actionConfig := &action.Configuration{}
-	if err := actionConfig.Init(c.settings.RESTClientGetter(), namespace, os.Getenv("HELM_DRIVER"), log.Printf); err != nil {
-		return fmt.Errorf("failed to initialize action config: %w", err)
+	if err := actionConfig.Init(newRESTClientGetter(c.restConfig, namespace), namespace, os.Getenv("HELM_DRIVER"), log.Printf); err != nil {
+		return nil, fmt.Errorf("failed to initialize action config: %w", err)
 	}
 
 	client := action.NewUninstall(actionConfig)
-	_, err := client.Run(releaseName)
+	client.DryRun = dryRun
+	response, err := client.Run(releaseName)
 	if err != nil {
-		return fmt.Errorf("failed to uninstall release: %w", err)
+		return nil, fmt.Errorf("failed to uninstall release: %w", err)
 	}
 
-	return nil
+	return response.Release, nil
 }
 
 func (c *Client) ListReleases(ctx context.Context, namespace string) ([]*release.Release, error) {
 	actionConfig := &action.Configuration{}
-	if err := actionConfig.Init(c.settings.RESTClientGetter(), namespace, os.Getenv("HELM_DRIVER"), log.Printf); err != nil {
+	if err := actionConfig.Init(newRESTClientGetter(c.restConfig, namespace), namespace, os.Getenv("HELM_DRIVER"), log.Printf); err != nil {
 		return nil, fmt.Errorf("failed to initialize action config: %w", err)
 	}
 
@@ -207,7 +212,7 @@ func (c *Client) ListReleases(ctx context.Context, namespace string) ([]*release
 
 func (c *Client) GetRelease(ctx context.Context, namespace, releaseName string) (*release.Release, error) {
 	actionConfig := &action.Configuration{}
-	if err := actionConfig.Init(c.settings.RESTClientGetter(), namespace, os.Getenv("HELM_DRIVER"), log.Printf); err != nil {
+	if err := actionConfig.Init(newRESTClientGetter(c.restConfig, namespace), namespace, os.Getenv("HELM_DRIVER"), log.Printf); err != nil {
 		return nil, fmt.Errorf("failed to initialize action config: %w", err)
 	}
 
@@ -222,7 +227,7 @@ func (c *Client) GetRelease(ctx context.Context, namespace, releaseName string)
 
 func (c *Client) GetReleaseHistory(ctx context.Context, namespace, releaseName string) ([]*release.Release, error) {
 	actionConfig := &action.Configuration{}
-	if err := actionConfig.Init(c.settings.RESTClientGetter(), namespace, os.Getenv("HELM_DRIVER"), log.Printf); err != nil {
+	if err := actionConfig.Init(newRESTClientGetter(c.restConfig, namespace), namespace, os.Getenv("HELM_DRIVER"), log.Printf); err != nil {
 		return nil, fmt.Errorf("failed to initialize action config: %w", err)
 	}
 
@@ -235,21 +240,42 @@ func (c *Client) GetReleaseHistory(ctx context.Context, namespace, releaseName s
 	return releases, nil
 }
 
-// RollbackRelease rolls back a Helm release
-func (c *Client) RollbackRelease(ctx context.Context, namespace, releaseName string, revision int) error {
+// RollbackRelease rolls back a Helm release.
+// With dryRun set, the rollback is validated and the revision that would be
+// restored is returned without changing anything; otherwise nil is returned.
+func (c *Client) RollbackRelease(ctx context.Context, namespace, releaseName string, revision int, dryRun bool) (*release.Release, error) {
 	actionConfig := &action.Configuration{}
-	if err := actionConfig.Init(c.settings.RESTClientGetter(), namespace, os.Getenv("HELM_DRIVER"), log.Printf); err != nil {
-		return fmt.Errorf("failed to initialize action config: %w", err)
+	if err := actionConfig.Init(newRESTClientGetter(c.restConfig, namespace), namespace, os.Getenv("HELM_DRIVER"), log.Printf); err != nil {
+		return nil, fmt.Errorf("failed to initialize action config: %w", err)
 	}
 
 	client := action.NewRollback(actionConfig)
 	client.Version = revision
+	client.DryRun = dryRun
 
 	if err := client.Run(releaseName); err != nil {
-		return fmt.Errorf("failed to rollback release: %w", err)
+		return nil, fmt.Errorf("failed to rollback release: %w", err)
+	}
+	if !dryRun {
+		return nil, nil
+	}
+
+	// Revision 0 means the revision before the current one
+	get := action.NewGet(actionConfig)
+	if revision == 0 {
+		current, err := get.Run(releaseName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get release: %w", err)
+		}
+		revision = current.Version - 1
+	}
+	get.Version = revision
+	target, err := get.Run(releaseName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revision %d of release: %w", revision, err)
 	}
 
-	return nil
+	return target, nil
 }
 
 // addRepo adds a Helm repository
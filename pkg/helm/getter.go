@@ -0,0 +1,61 @@
+package helm
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// restClientGetter implements genericclioptions.RESTClientGetter on top of an
+// existing rest.Config, so Helm actions use exactly the same cluster and
+// credentials as the Kubernetes client built from that config.
+type restClientGetter struct {
+	restConfig *rest.Config
+	namespace  string
+}
+
+// newRESTClientGetter returns a RESTClientGetter for the given config whose
+// default namespace is namespace.
+func newRESTClientGetter(restConfig *rest.Config, namespace string) *restClientGetter {
+	return &restClientGetter{
+		restConfig: restConfig,
+		namespace:  namespace,
+	}
+}
+
+// ToRESTConfig returns a copy of the shared REST config.
+func (g *restClientGetter) ToRESTConfig() (*rest.Config, error) {
+	return rest.CopyConfig(g.restConfig), nil
+}
+
+// ToDiscoveryClient returns a memory-cached discovery client.
+func (g *restClientGetter) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(g.restConfig)
+	if err != nil {
+		return nil, err
+	}
+	return memory.NewMemCacheClient(discoveryClient), nil
+}
+
+// ToRESTMapper returns a deferred REST mapper that understands kubectl short names.
+func (g *restClientGetter) ToRESTMapper() (meta.RESTMapper, error) {
+	discoveryClient, err := g.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(discoveryClient)
+	return restmapper.NewShortcutExpander(mapper, discoveryClient, nil), nil
+}
+
+// ToRawKubeConfigLoader returns a client config that only carries the namespace.
+// Helm uses it to default the namespace of rendered resources.
+func (g *restClientGetter) ToRawKubeConfigLoader() clientcmd.ClientConfig {
+	overrides := &clientcmd.ConfigOverrides{
+		Context: clientcmdapi.Context{Namespace: g.namespace},
+	}
+	return clientcmd.NewDefaultClientConfig(*clientcmdapi.NewConfig(), overrides)
+}
@@ -0,0 +1,89 @@
+package helm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// ManifestResource identifies a resource rendered in a release manifest.
+type ManifestResource struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// UninstallImpact summarizes what uninstalling a release would remove.
+type UninstallImpact struct {
+	Release   string             `json:"release"`
+	Namespace string             `json:"namespace"`
+	Chart     string             `json:"chart"`
+	Version   int                `json:"revision"`
+	Status    string             `json:"status"`
+	Resources []ManifestResource `json:"resources"`
+}
+
+// UninstallImpact returns the release that helmUninstall would remove and the
+// resources listed in its manifest.
+func (c *Client) UninstallImpact(ctx context.Context, namespace, releaseName string) (*UninstallImpact, error) {
+	release, err := c.GetRelease(ctx, namespace, releaseName)
+	if err != nil {
+		return nil, err
+	}
+
+	resources, err := manifestResources(release.Manifest, release.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	impact := &UninstallImpact{
+		Release:   release.Name,
+		Namespace: release.Namespace,
+		Version:   release.Version,
+		Resources: resources,
+	}
+	if release.Chart != nil && release.Chart.Metadata != nil {
+		impact.Chart = release.Chart.Metadata.Name + "-" + release.Chart.Metadata.Version
+	}
+	if release.Info != nil {
+		impact.Status = release.Info.Status.String()
+	}
+	return impact, nil
+}
+
+// manifestResources lists the objects of a multi-document release manifest.
+// Objects without a namespace are reported in the release namespace.
+func manifestResources(manifest, namespace string) ([]ManifestResource, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader([]byte(manifest)), 4096)
+
+	var resources []ManifestResource
+	for {
+		var object struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+		}
+		if err := decoder.Decode(&object); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to parse release manifest: %w", err)
+		}
+		if object.Kind == "" {
+			continue
+		}
+
+		resource := ManifestResource{Kind: object.Kind, Name: object.Metadata.Name, Namespace: object.Metadata.Namespace}
+		if resource.Namespace == "" {
+			resource.Namespace = namespace
+		}
+		resources = append(resources, resource)
+	}
+	return resources, nil
+}
@@ -0,0 +1,204 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultFieldManager is the field manager used for server-side apply unless
+// another one is configured.
+const DefaultFieldManager = "k8s-mcp-server"
+
+// ApplyOptions configures a server-side apply.
+type ApplyOptions struct {
+	// FieldManager records which manager owns the applied fields.
+	FieldManager string
+	// Force takes ownership of fields owned by other managers instead of
+	// rejecting the apply with a conflict.
+	Force bool
+	// DryRun validates the apply server-side without persisting it.
+	DryRun bool
+}
+
+// FieldConflict is a field whose ownership prevented a server-side apply.
+type FieldConflict struct {
+	// Field is the conflicting field path, e.g. ".spec.replicas".
+	Field string `json:"field"`
+	// Manager is the field manager that currently owns the field, if known.
+	Manager string `json:"manager,omitempty"`
+	Message string `json:"message"`
+}
+
+// ApplyConflictError is returned when a server-side apply is rejected because
+// fields in the manifest are owned by other field managers.
+type ApplyConflictError struct {
+	Kind      string          `json:"kind"`
+	Name      string          `json:"name"`
+	Namespace string          `json:"namespace,omitempty"`
+	Conflicts []FieldConflict `json:"conflicts"`
+}
+
+func (e *ApplyConflictError) Error() string {
+	fields := make([]string, 0, len(e.Conflicts))
+	for _, conflict := range e.Conflicts {
+		fields = append(fields, conflict.Field)
+	}
+	return fmt.Sprintf("apply of %s %s conflicts with other field managers on %s", e.Kind, e.Name, strings.Join(fields, ", "))
+}
+
+// conflictManagerPattern extracts the manager from conflict messages such as
+// `conflict with "kubectl-client-side-apply" using apps/v1: .spec.replicas`.
+var conflictManagerPattern = regexp.MustCompile(`conflict with "([^"]+)"`)
+
+// ApplyResource creates or updates a resource from a YAML or JSON manifest
+// with server-side apply. Unlike a merge patch, fields removed from the
+// manifest are removed from the object if this field manager owned them, and
+// fields owned by other managers are only overwritten with options.Force.
+// Cluster-scoped kinds ignore any namespace; namespaced kinds default to
+// "default" when neither the manifest nor the namespace argument sets one.
+// A rejected apply due to field ownership returns an *ApplyConflictError.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - namespace: Target namespace (overrides the manifest namespace if provided)
+//   - manifest: YAML or JSON manifest of a single Kubernetes resource
+//   - kind: Resource kind (optional, inferred from the manifest if empty)
+//   - options: Field manager, force and dry-run settings
+func (c *Client) ApplyResource(ctx context.Context, namespace, manifest, kind string, options ApplyOptions) (map[string]interface{}, error) {
+	obj, resource, err := c.applyTarget(namespace, manifest, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := serverSideApply(ctx, resource, obj, options)
+	if err != nil {
+		if conflicts := fieldConflicts(err); len(conflicts) > 0 {
+			return nil, &ApplyConflictError{
+				Kind:      obj.GetKind(),
+				Name:      obj.GetName(),
+				Namespace: obj.GetNamespace(),
+				Conflicts: conflicts,
+			}
+		}
+		return nil, fmt.Errorf("failed to apply resource: %w", err)
+	}
+
+	return result.UnstructuredContent(), nil
+}
+
+// applyTarget decodes a manifest for server-side apply and returns it with the
+// dynamic resource interface to apply it to.
+func (c *Client) applyTarget(namespace, manifest, kind string) (*unstructured.Unstructured, dynamic.ResourceInterface, error) {
+	jsonData, err := yaml.YAMLToJSON([]byte(manifest))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := json.Unmarshal(jsonData, &obj.Object); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if err := validateApplyObject(obj); err != nil {
+		return nil, nil, err
+	}
+
+	resource, err := c.applyResourceFor(obj, namespace, kind)
+	if err != nil {
+		return nil, nil, err
+	}
+	return obj, resource, nil
+}
+
+// validateApplyObject checks that obj has the fields server-side apply needs.
+func validateApplyObject(obj *unstructured.Unstructured) error {
+	if obj.GetName() == "" {
+		return fmt.Errorf("resource name is required in manifest")
+	}
+	if obj.GetAPIVersion() == "" || obj.GetKind() == "" {
+		return fmt.Errorf("apiVersion and kind are required in manifest for server-side apply")
+	}
+	return nil
+}
+
+// applyResourceFor returns the dynamic resource interface obj is applied to,
+// resolved from kind if set and from the object's apiVersion and kind otherwise.
+// Cluster-scoped kinds ignore any namespace; namespaced kinds take namespace if
+// set, and default to "default" when neither it nor the object sets one.
+func (c *Client) applyResourceFor(obj *unstructured.Unstructured, namespace, kind string) (dynamic.ResourceInterface, error) {
+	var info *ResourceInfo
+	var err error
+	if kind != "" {
+		info, err = c.ResolveResource(kind, "")
+	} else {
+		info, err = c.ResolveResource(obj.GetKind(), obj.GetAPIVersion())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.Namespaced {
+		obj.SetNamespace("")
+		return c.dynamicClient.Resource(info.GVR), nil
+	}
+	if namespace != "" {
+		obj.SetNamespace(namespace)
+	}
+	if obj.GetNamespace() == "" {
+		obj.SetNamespace("default")
+	}
+	return c.dynamicClient.Resource(info.GVR).Namespace(obj.GetNamespace()), nil
+}
+
+// serverSideApply applies obj to resource with an apply patch.
+func serverSideApply(ctx context.Context, resource dynamic.ResourceInterface, obj *unstructured.Unstructured, options ApplyOptions) (*unstructured.Unstructured, error) {
+	applyData, err := json.Marshal(obj.Object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	fieldManager := options.FieldManager
+	if fieldManager == "" {
+		fieldManager = DefaultFieldManager
+	}
+	return resource.Patch(ctx, obj.GetName(), types.ApplyPatchType, applyData, metav1.PatchOptions{
+		FieldManager: fieldManager,
+		Force:        &options.Force,
+		DryRun:       dryRunOptions(options.DryRun),
+	})
+}
+
+// fieldConflicts extracts the field manager conflicts from an apply error.
+func fieldConflicts(err error) []FieldConflict {
+	var statusErr apierrors.APIStatus
+	if !apierrors.IsConflict(err) || !errors.As(err, &statusErr) {
+		return nil
+	}
+	details := statusErr.Status().Details
+	if details == nil {
+		return nil
+	}
+
+	var conflicts []FieldConflict
+	for _, cause := range details.Causes {
+		if cause.Type != metav1.CauseTypeFieldManagerConflict {
+			continue
+		}
+		conflict := FieldConflict{Field: cause.Field, Message: cause.Message}
+		if match := conflictManagerPattern.FindStringSubmatch(cause.Message); match != nil {
+			conflict.Manager = match[1]
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts
+}
@@ -0,0 +1,299 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+)
+
+// Statuses of an object in a bulk apply.
+const (
+	ApplyStatusCreated    = "created"
+	ApplyStatusConfigured = "configured"
+	ApplyStatusUnchanged  = "unchanged"
+	ApplyStatusFailed     = "failed"
+)
+
+// ApplyResult is the outcome of applying one object of a manifest.
+type ApplyResult struct {
+	// Document is the 1-based position of the object's YAML document among
+	// the non-empty documents of the manifest.
+	Document   int    `json:"document"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace,omitempty"`
+	Status     string `json:"status"`
+	Message    string `json:"message,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// Conflicts lists the fields owned by other field managers when the
+	// apply was rejected because of them.
+	Conflicts []FieldConflict `json:"conflicts,omitempty"`
+}
+
+// applyOrder is the order in which kinds are applied, so that objects are
+// applied after the objects they depend on: Namespaces and
+// CustomResourceDefinitions first, workloads after their configuration.
+// Kinds not listed, such as custom resources, are applied last.
+var applyOrder = []string{
+	"Namespace",
+	"CustomResourceDefinition",
+	"PriorityClass",
+	"StorageClass",
+	"ResourceQuota",
+	"LimitRange",
+	"NetworkPolicy",
+	"PodDisruptionBudget",
+	"ServiceAccount",
+	"Secret",
+	"ConfigMap",
+	"PersistentVolume",
+	"PersistentVolumeClaim",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"Role",
+	"RoleBinding",
+	"Service",
+	"DaemonSet",
+	"Pod",
+	"ReplicationController",
+	"ReplicaSet",
+	"Deployment",
+	"HorizontalPodAutoscaler",
+	"StatefulSet",
+	"Job",
+	"CronJob",
+	"IngressClass",
+	"Ingress",
+	"APIService",
+}
+
+// crdEstablishTimeout bounds how long a bulk apply waits for the API server to
+// serve a custom resource whose CustomResourceDefinition it just applied.
+const crdEstablishTimeout = 30 * time.Second
+
+// manifestObject is an object decoded from a manifest with its document position.
+type manifestObject struct {
+	document int
+	object   *unstructured.Unstructured
+}
+
+// ApplyManifests applies every object of a JSON or multi-document YAML manifest
+// with server-side apply and reports the outcome of each object.
+// The resource of each object is resolved from its own apiVersion and kind.
+// Items of List objects are applied individually. Objects are applied in
+// applyOrder, so Namespaces and CustomResourceDefinitions come first; a failed
+// object does not stop the others. The manifest is rejected as a whole, before
+// anything is applied, if any document cannot be decoded or lacks apiVersion,
+// kind or name.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - namespace: Target namespace for namespaced objects (overrides the manifest namespaces if provided)
+//   - manifest: JSON or YAML manifest, with documents separated by "---"
+//   - options: Field manager, force and dry-run settings
+func (c *Client) ApplyManifests(ctx context.Context, namespace, manifest string, options ApplyOptions) ([]ApplyResult, error) {
+	objects, err := decodeManifestObjects(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("manifest contains no objects")
+	}
+	for _, item := range objects {
+		if err := validateApplyObject(item.object); err != nil {
+			return nil, fmt.Errorf("document %d: %w", item.document, err)
+		}
+	}
+
+	sort.SliceStable(objects, func(i, j int) bool {
+		return applyRank(objects[i].object.GetKind()) < applyRank(objects[j].object.GetKind())
+	})
+
+	// Custom resources can only be resolved once their definition is served
+	newKinds := make(map[schema.GroupKind]bool)
+	for _, item := range objects {
+		if groupKind, ok := definedGroupKind(item.object); ok {
+			newKinds[groupKind] = true
+		}
+	}
+
+	// In a dry run, namespaces to be created do not exist for the objects in them
+	createdNamespaces := make(map[string]bool)
+
+	results := make([]ApplyResult, 0, len(objects))
+	for _, item := range objects {
+		result := c.applyManifestObject(ctx, item, namespace, options, newKinds, createdNamespaces)
+		if options.DryRun && result.Kind == "Namespace" && result.Status == ApplyStatusCreated {
+			createdNamespaces[result.Name] = true
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// applyManifestObject applies a single object of a bulk apply.
+func (c *Client) applyManifestObject(ctx context.Context, item manifestObject, namespace string, options ApplyOptions, newKinds map[schema.GroupKind]bool, createdNamespaces map[string]bool) ApplyResult {
+	obj := item.object
+	result := ApplyResult{
+		Document:   item.document,
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Name:       obj.GetName(),
+		Namespace:  obj.GetNamespace(),
+	}
+	fail := func(err error) ApplyResult {
+		result.Status = ApplyStatusFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	isNewKind := newKinds[obj.GroupVersionKind().GroupKind()]
+	resource, err := c.applyResourceFor(obj, namespace, "")
+	if err != nil && isNewKind {
+		if options.DryRun {
+			result.Status = ApplyStatusCreated
+			result.Message = "Its CustomResourceDefinition is only created by this apply, so the object was not validated by the API server"
+			return result
+		}
+		resource, err = c.waitForApplyResource(ctx, obj, namespace)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	result.Namespace = obj.GetNamespace()
+
+	if createdNamespaces[obj.GetNamespace()] {
+		result.Status = ApplyStatusCreated
+		result.Message = fmt.Sprintf("Namespace %s is only created by this apply, so the object was not validated by the API server", obj.GetNamespace())
+		return result
+	}
+
+	live, err := resource.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		live = nil
+	} else if err != nil {
+		return fail(fmt.Errorf("failed to get live resource: %w", err))
+	}
+
+	applied, err := serverSideApply(ctx, resource, obj, options)
+	if err != nil {
+		if conflicts := fieldConflicts(err); len(conflicts) > 0 {
+			result.Conflicts = conflicts
+			return fail(&ApplyConflictError{Kind: result.Kind, Name: result.Name, Namespace: result.Namespace, Conflicts: conflicts})
+		}
+		return fail(fmt.Errorf("failed to apply resource: %w", err))
+	}
+
+	if live == nil {
+		result.Status = ApplyStatusCreated
+		return result
+	}
+
+	liveYAML, err := diffableYAML(live)
+	if err != nil {
+		return fail(err)
+	}
+	appliedYAML, err := diffableYAML(applied)
+	if err != nil {
+		return fail(err)
+	}
+	if liveYAML == appliedYAML {
+		result.Status = ApplyStatusUnchanged
+	} else {
+		result.Status = ApplyStatusConfigured
+	}
+	return result
+}
+
+// waitForApplyResource resolves the resource of a custom resource whose
+// CustomResourceDefinition was just applied, waiting until the API server
+// serves it.
+func (c *Client) waitForApplyResource(ctx context.Context, obj *unstructured.Unstructured, namespace string) (dynamic.ResourceInterface, error) {
+	var resource dynamic.ResourceInterface
+	var lastErr error
+	err := wait.PollUntilContextTimeout(ctx, time.Second, crdEstablishTimeout, true, func(ctx context.Context) (bool, error) {
+		resource, lastErr = c.applyResourceFor(obj, namespace, "")
+		return lastErr == nil, nil
+	})
+	if err != nil {
+		if lastErr != nil {
+			return nil, fmt.Errorf("custom resource was not served after applying its definition: %w", lastErr)
+		}
+		return nil, err
+	}
+	return resource, nil
+}
+
+// definedGroupKind returns the group and kind defined by a CustomResourceDefinition.
+func definedGroupKind(obj *unstructured.Unstructured) (schema.GroupKind, bool) {
+	if obj.GetKind() != "CustomResourceDefinition" {
+		return schema.GroupKind{}, false
+	}
+	group, _, _ := unstructured.NestedString(obj.Object, "spec", "group")
+	kind, _, _ := unstructured.NestedString(obj.Object, "spec", "names", "kind")
+	if kind == "" {
+		return schema.GroupKind{}, false
+	}
+	return schema.GroupKind{Group: group, Kind: kind}, true
+}
+
+// applyRank returns the position of kind in applyOrder.
+func applyRank(kind string) int {
+	for i, ordered := range applyOrder {
+		if ordered == kind {
+			return i
+		}
+	}
+	return len(applyOrder)
+}
+
+// decodeManifestObjects decodes every object of a JSON or multi-document YAML
+// manifest, expanding the items of List objects. Empty documents are skipped.
+func decodeManifestObjects(manifest string) ([]manifestObject, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader([]byte(manifest)), 4096)
+
+	var objects []manifestObject
+	// document counts the non-empty documents only, so that empty and
+	// comment-only documents between separators do not shift the positions
+	document := 0
+	for {
+		var content map[string]interface{}
+		if err := decoder.Decode(&content); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to parse manifest document %d: %w", document+1, err)
+		}
+		if len(content) == 0 {
+			continue
+		}
+		document++
+
+		obj := &unstructured.Unstructured{Object: content}
+		if !obj.IsList() {
+			objects = append(objects, manifestObject{document: document, object: obj})
+			continue
+		}
+		list, err := obj.ToList()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse manifest document %d: %w", document, err)
+		}
+		for i := range list.Items {
+			objects = append(objects, manifestObject{document: document, object: &list.Items[i]})
+		}
+	}
+	return objects, nil
+}
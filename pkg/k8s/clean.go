@@ -0,0 +1,59 @@
+package k8s
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// noisyAnnotations are annotations that only repeat information found
+// elsewhere or are maintained by controllers for their own bookkeeping. They
+// are often larger than the rest of the object, such as the full copy of the
+// manifest in last-applied-configuration.
+var noisyAnnotations = []string{
+	"kubectl.kubernetes.io/last-applied-configuration",
+	"control-plane.alpha.kubernetes.io/leader",
+	"endpoints.kubernetes.io/last-change-trigger-time",
+	"deprecated.daemonset.template.generation",
+}
+
+// GetOptions controls which parts of a resource GetResource returns.
+type GetOptions struct {
+	// Strip removes metadata.managedFields and noisyAnnotations.
+	Strip bool
+	// Sections, if set, keeps only these top-level fields, such as metadata,
+	// spec, status or data, besides apiVersion and kind.
+	Sections []string
+}
+
+// cleanResource returns the content of obj reduced as options ask for.
+// obj itself is left unchanged.
+func cleanResource(obj *unstructured.Unstructured, options GetOptions) map[string]interface{} {
+	content := obj.DeepCopy().UnstructuredContent()
+
+	if len(options.Sections) > 0 {
+		keep := map[string]bool{"apiVersion": true, "kind": true}
+		for _, section := range options.Sections {
+			keep[section] = true
+		}
+		for field := range content {
+			if !keep[field] {
+				delete(content, field)
+			}
+		}
+	}
+
+	if options.Strip {
+		unstructured.RemoveNestedField(content, "metadata", "managedFields")
+		annotations, found, _ := unstructured.NestedStringMap(content, "metadata", "annotations")
+		if found {
+			for _, annotation := range noisyAnnotations {
+				delete(annotations, annotation)
+			}
+			if len(annotations) == 0 {
+				unstructured.RemoveNestedField(content, "metadata", "annotations")
+			} else {
+				_ = unstructured.SetNestedStringMap(content, annotations, "metadata", "annotations")
+			}
+		}
+	}
+	return content
+}
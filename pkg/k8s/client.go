@@ -2,16 +2,15 @@
 package k8s
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"path/filepath"
-	"strings"
+	"log"
 	"sync"
 	"time"
 
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/kubeconfig"
+
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/yaml"
@@ -22,11 +21,10 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
-	"k8s.io/client-go/util/homedir"
 	metricsclientset "k8s.io/metrics/pkg/client/clientset/versioned"
 )
 
@@ -36,30 +34,39 @@ import (
 type Client struct {
 	clientset        *kubernetes.Clientset
 	dynamicClient    dynamic.Interface
-	discoveryClient  *discovery.DiscoveryClient
+	discoveryClient  discovery.CachedDiscoveryInterface
 	metricsClientset *metricsclientset.Clientset // Add metrics client
 	restConfig       *rest.Config
-	apiResourceCache map[string]*schema.GroupVersionResource
-	cacheLock        sync.RWMutex
+	resourceCache    map[string]*ResourceInfo
+	// discoveryTTL and discoveryRefreshed control when discoveryClient's
+	// cache and resourceCache expire (see discovery.go)
+	discoveryTTL       time.Duration
+	discoveryRefreshed time.Time
+	cacheLock          sync.RWMutex
 }
 
 // NewClient creates a new Kubernetes client.
 // It initializes the standard clientset, dynamic client, discovery client,
-// and metrics client using the provided kubeconfig path or the default path.
-// If kubeconfigPath is empty, it defaults to ~/.kube/config.
-func NewClient(kubeconfigPath string) (*Client, error) {
-	var kubeconfig string
-	if kubeconfigPath != "" {
-		kubeconfig = kubeconfigPath
-	} else if home := homedir.HomeDir(); home != "" {
-		kubeconfig = filepath.Join(home, ".kube", "config")
+// and metrics client using the REST config produced by the shared kubeconfig
+// loader, so it resolves the cluster exactly like the Helm client does.
+func NewClient(options kubeconfig.Options) (*Client, error) {
+	loader, err := kubeconfig.NewLoader(options)
+	if err != nil {
+		return nil, err
 	}
 
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	config, err := loader.RESTConfig("")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Kubernetes configuration: %w", err)
 	}
 
+	return NewClientForConfig(config)
+}
+
+// NewClientForConfig creates a new Kubernetes client from an existing REST config.
+// It is used when the configuration is shared with other clients, such as the
+// Helm client for the same kubeconfig context.
+func NewClientForConfig(config *rest.Config) (*Client, error) {
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
@@ -82,12 +89,14 @@ func NewClient(kubeconfigPath string) (*Client, error) {
 	}
 
 	return &Client{
-		clientset:        clientset,
-		dynamicClient:    dynamicClient,
-		discoveryClient:  discoveryClient,
-		metricsClientset: metricsClient, // Assign metrics client
-		restConfig:       config,
-		apiResourceCache: make(map[string]*schema.GroupVersionResource),
+		clientset:          clientset,
+		dynamicClient:      dynamicClient,
+		discoveryClient:    memory.NewMemCacheClient(discoveryClient),
+		metricsClientset:   metricsClient, // Assign metrics client
+		restConfig:         config,
+		resourceCache:      make(map[string]*ResourceInfo),
+		discoveryTTL:       DefaultDiscoveryTTL,
+		discoveryRefreshed: time.Now(),
 	}, nil
 }
 
@@ -96,6 +105,7 @@ func NewClient(kubeconfigPath string) (*Client, error) {
 // Filters resources based on includeNamespaceScoped and includeClusterScoped flags.
 // Returns a slice of maps, each representing an API resource, or an error.
 func (c *Client) GetAPIResources(ctx context.Context, includeNamespaceScoped, includeClusterScoped bool) ([]map[string]interface{}, error) {
+	c.expireDiscovery()
 	resourceLists, err := c.discoveryClient.ServerPreferredResources()
 	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
 		return nil, fmt.Errorf("failed to retrieve API resources: %w", err)
@@ -124,61 +134,79 @@ func (c *Client) GetAPIResources(ctx context.Context, includeNamespaceScoped, in
 // GetResource retrieves detailed information about a specific resource.
 // It uses the dynamic client to fetch the resource by kind, name, and namespace.
 // It utilizes a cached GroupVersionResource (GVR) for efficiency.
-// Returns the unstructured content of the resource as a map, or an error.
-func (c *Client) GetResource(ctx context.Context, kind, name, namespace string) (map[string]interface{}, error) {
-	gvr, err := c.getCachedGVR(kind)
+// Returns the unstructured content of the resource as a map, reduced as
+// options ask for, or an error.
+func (c *Client) GetResource(ctx context.Context, kind, name, namespace string, options GetOptions) (map[string]interface{}, error) {
+	resource, err := c.resourceInterface(kind, namespace)
 	if err != nil {
 		return nil, err
 	}
 
-	var obj *unstructured.Unstructured
-	if namespace != "" {
-		obj, err = c.dynamicClient.Resource(*gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
-	} else {
-		obj, err = c.dynamicClient.Resource(*gvr).Get(ctx, name, metav1.GetOptions{})
-	}
+	obj, err := resource.Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve resource: %w", err)
 	}
 
-	return obj.UnstructuredContent(), nil
+	return cleanResource(obj, options), nil
+}
+
+// ResourceList is one page of a resource listing.
+type ResourceList struct {
+	Items []map[string]interface{} `json:"items"`
+	// Continue is set when more items are available; pass it to the next
+	// ListResources call to get the next page.
+	Continue string `json:"continue,omitempty"`
+	// RemainingItemCount is the number of items after this page, if the API
+	// server could tell.
+	RemainingItemCount *int64 `json:"remainingItemCount,omitempty"`
 }
 
-// ListResources lists all instances of a specific resource type.
-// It uses the dynamic client and supports filtering by namespace, labelSelector,
-// and fieldSelector.
+// ListOptions configures ListResources.
+type ListOptions struct {
+	LabelSelector string
+	FieldSelector string
+	// Limit is the maximum number of items returned (all of them if 0).
+	Limit int64
+	// Continue is the Continue value of the previous page, or empty for the first page.
+	Continue string
+	// Columns replaces the default summary of each item (see ParseColumns).
+	Columns []Column
+}
+
+// ListResources lists instances of a specific resource type, one page at a time.
+// It uses the dynamic client and supports filtering by namespace and by the
+// label and field selectors of options.
 // It utilizes a cached GroupVersionResource (GVR) for efficiency.
-// Returns a slice of maps, each representing a resource instance, or an error.
-func (c *Client) ListResources(ctx context.Context, kind, namespace, labelSelector, fieldSelector string) ([]map[string]interface{}, error) {
-	gvr, err := c.getCachedGVR(kind)
+// Each item is summarized by its name, kind, namespace, labels, age, owner and
+// kind-specific columns such as a Pod's phase, or by its name, kind, namespace
+// and options.Columns if set.
+// Returns the page of resource instances, or an error.
+func (c *Client) ListResources(ctx context.Context, kind, namespace string, options ListOptions) (*ResourceList, error) {
+	resource, err := c.resourceInterface(kind, namespace)
 	if err != nil {
 		return nil, err
 	}
 
-	options := metav1.ListOptions{
-		LabelSelector: labelSelector,
-		FieldSelector: fieldSelector,
-	}
-
-	var list *unstructured.UnstructuredList
-	if namespace != "" {
-		list, err = c.dynamicClient.Resource(*gvr).Namespace(namespace).List(ctx, options)
-	} else {
-		list, err = c.dynamicClient.Resource(*gvr).List(ctx, options)
+	list, err := resource.List(ctx, metav1.ListOptions{
+		LabelSelector: options.LabelSelector,
+		FieldSelector: options.FieldSelector,
+		Limit:         options.Limit,
+		Continue:      options.Continue,
+	})
+	if errors.IsResourceExpired(err) {
+		return nil, fmt.Errorf("the continue token has expired; list again without it to start from the first page: %w", err)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to list resources: %w", err)
 	}
 
-	var resources []map[string]interface{}
-	for _, item := range list.Items {
-		metadata := item.GetLabels()
-		resources = append(resources, map[string]interface{}{
-			"name":      item.GetName(),
-			"kind":      item.GetKind(),
-			"namespace": item.GetNamespace(),
-			"labels":    metadata,
-		})
+	resources := &ResourceList{
+		Items:              make([]map[string]interface{}, 0, len(list.Items)),
+		Continue:           list.GetContinue(),
+		RemainingItemCount: list.GetRemainingItemCount(),
+	}
+	for i := range list.Items {
+		resources.Items = append(resources.Items, listItem(&list.Items[i], options.Columns))
 	}
 
 	return resources, nil
@@ -189,28 +217,33 @@ func (c *Client) ListResources(ctx context.Context, kind, namespace, labelSelect
 // It uses the dynamic client to first attempt an update, and if that fails
 // (e.g., resource not found), it attempts to create the resource.
 // Requires the resource manifest to include a name.
+// With dryRun set, the API server validates the change without persisting it,
+// unless the namespace does not exist: the object is then returned as it would
+// be submitted, with a note that it was not validated.
 // Returns the unstructured content of the created/updated resource, or an error.
-func (c *Client) CreateOrUpdateResourceJSON(ctx context.Context, namespace, manifestJSON, kind string) (map[string]interface{}, error) {
+func (c *Client) CreateOrUpdateResourceJSON(ctx context.Context, namespace, manifestJSON, kind string, dryRun bool) (map[string]interface{}, string, error) {
 	// Decode JSON into unstructured object directly (no YAML conversion)
 
 	obj := &unstructured.Unstructured{}
 	if err := json.Unmarshal([]byte(manifestJSON), &obj.Object); err != nil {
-		return nil, fmt.Errorf("failed to parse resource manifest JSON: %w", err)
+		return nil, "", fmt.Errorf("failed to parse resource manifest JSON: %w", err)
 	}
 
 	// Determine the resource GVR
 	gvr, err := c.getCachedGVR(kind)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	// Check if ns exists
+	namespaceMissing := false
 	_, err = c.clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
 	if err == nil {
-		fmt.Printf("Namespace %s exists\n", namespace)
+		log.Printf("Namespace %s exists", namespace)
 	}
 	if errors.IsNotFound(err) {
-		fmt.Printf("Namespace %s does not exist, creating one\n", namespace)
+		namespaceMissing = true
+		log.Printf("Namespace %s does not exist, creating one", namespace)
 		_, err = c.clientset.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{
 			ObjectMeta: metav1.ObjectMeta{
 				Labels: map[string]string{
@@ -225,16 +258,22 @@ func (c *Client) CreateOrUpdateResourceJSON(ctx context.Context, namespace, mani
 				Phase:      corev1.NamespaceActive,
 				Conditions: nil,
 			},
-		}, metav1.CreateOptions{})
+		}, metav1.CreateOptions{DryRun: dryRunOptions(dryRun)})
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve namespace resource: %w", err)
+		return nil, "", fmt.Errorf("failed to retrieve namespace resource: %w", err)
 	}
 
 	obj.SetNamespace(namespace)
 
 	if obj.GetName() == "" {
-		return nil, fmt.Errorf("resource name is required")
+		return nil, "", fmt.Errorf("resource name is required")
+	}
+
+	// The API server rejects objects in a namespace that was only dry-run
+	// created, so return the object as it would be submitted, as applyManifests does
+	if dryRun && namespaceMissing {
+		return obj.UnstructuredContent(), fmt.Sprintf("Namespace %s does not exist and is only created by this call, so the object was not validated by the API server", namespace), nil
 	}
 
 	resource := c.dynamicClient.Resource(*gvr).Namespace(obj.GetNamespace())
@@ -246,16 +285,16 @@ func (c *Client) CreateOrUpdateResourceJSON(ctx context.Context, namespace, mani
 		obj.GetName(),
 		types.MergePatchType,
 		rawJSON,
-		metav1.PatchOptions{},
+		metav1.PatchOptions{DryRun: dryRunOptions(dryRun)},
 	)
 	if errors.IsNotFound(err) {
-		result, err = resource.Create(ctx, obj, metav1.CreateOptions{})
+		result, err = resource.Create(ctx, obj, metav1.CreateOptions{DryRun: dryRunOptions(dryRun)})
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to create or patch resource: %w", err)
+		return nil, "", fmt.Errorf("failed to create or patch resource: %w", err)
 	}
 
-	return result.UnstructuredContent(), nil
+	return result.UnstructuredContent(), "", nil
 }
 
 // CreateOrUpdateResourceYAML creates a new resource or updates an existing one from a YAML manifest.
@@ -270,6 +309,7 @@ func (c *Client) CreateOrUpdateResourceJSON(ctx context.Context, namespace, mani
 //   - namespace: Target namespace for the resource (overrides manifest namespace if provided)
 //   - yamlManifest: YAML manifest string of the Kubernetes resource
 //   - kind: Resource kind (optional, will be inferred from manifest if empty)
+//   - dryRun: Validate the change server-side without persisting it
 //
 // Example YAML manifest:
 //
@@ -282,7 +322,16 @@ func (c *Client) CreateOrUpdateResourceJSON(ctx context.Context, namespace, mani
 //	  containers:
 //	  - name: nginx
 //	    image: nginx:latest
-func (c *Client) CreateOrUpdateResourceYAML(ctx context.Context, namespace, yamlManifest, kind string) (map[string]interface{}, error) {
+func (c *Client) CreateOrUpdateResourceYAML(ctx context.Context, namespace, yamlManifest, kind string, dryRun bool) (map[string]interface{}, error) {
+	// YAMLToJSON only converts the first document, so refuse to drop the others
+	objects, err := decodeManifestObjects(yamlManifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse YAML manifest: %w", err)
+	}
+	if len(objects) > 1 {
+		return nil, fmt.Errorf("YAML manifest contains %d objects; use applyManifests to apply several objects at once", len(objects))
+	}
+
 	// Convert YAML to JSON
 	jsonData, err := yaml.YAMLToJSON([]byte(yamlManifest))
 	if err != nil {
@@ -295,8 +344,16 @@ func (c *Client) CreateOrUpdateResourceYAML(ctx context.Context, namespace, yaml
 		return nil, fmt.Errorf("failed to parse converted JSON from YAML manifest: %w", err)
 	}
 
-	// Determine the resource GVR
-	gvr, err := c.getCachedGVR(kind)
+	// Determine the resource GVR, from the manifest's apiVersion and kind if no kind is given
+	var gvr *schema.GroupVersionResource
+	if kind != "" {
+		gvr, err = c.getCachedGVR(kind)
+	} else {
+		var info *ResourceInfo
+		if info, err = c.ResolveResource(obj.GetKind(), obj.GetAPIVersion()); err == nil {
+			gvr = &info.GVR
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -318,10 +375,10 @@ func (c *Client) CreateOrUpdateResourceYAML(ctx context.Context, namespace, yaml
 		obj.GetName(),
 		types.MergePatchType,
 		jsonData,
-		metav1.PatchOptions{},
+		metav1.PatchOptions{DryRun: dryRunOptions(dryRun)},
 	)
 	if errors.IsNotFound(err) {
-		result, err = resource.Create(ctx, obj, metav1.CreateOptions{})
+		result, err = resource.Create(ctx, obj, metav1.CreateOptions{DryRun: dryRunOptions(dryRun)})
 	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create or patch resource from YAML manifest: %w", err)
@@ -333,163 +390,51 @@ func (c *Client) CreateOrUpdateResourceYAML(ctx context.Context, namespace, yaml
 // DeleteResource deletes a specific resource.
 // It uses the dynamic client to delete the resource by kind, name, and namespace.
 // It utilizes a cached GroupVersionResource (GVR) for efficiency.
+// With dryRun set, the API server validates the deletion without performing it.
 // Returns an error if the deletion fails.
-func (c *Client) DeleteResource(ctx context.Context, kind, name, namespace string) error {
-	gvr, err := c.getCachedGVR(kind)
+func (c *Client) DeleteResource(ctx context.Context, kind, name, namespace string, dryRun bool) error {
+	resource, err := c.resourceInterface(kind, namespace)
 	if err != nil {
 		return err
 	}
 
-	var deleteErr error
-	if namespace != "" {
-		deleteErr = c.dynamicClient.Resource(*gvr).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{})
-	} else {
-		deleteErr = c.dynamicClient.Resource(*gvr).Delete(ctx, name, metav1.DeleteOptions{})
-	}
-	if deleteErr != nil {
-		return fmt.Errorf("failed to delete resource: %w", deleteErr)
+	if err := resource.Delete(ctx, name, metav1.DeleteOptions{DryRun: dryRunOptions(dryRun)}); err != nil {
+		return fmt.Errorf("failed to delete resource: %w", err)
 	}
 	return nil
 }
 
-// getCachedGVR retrieves the GroupVersionResource for a given kind, using a cache for performance
-func (c *Client) getCachedGVR(kind string) (*schema.GroupVersionResource, error) {
-	c.cacheLock.RLock()
-	if gvr, exists := c.apiResourceCache[kind]; exists {
-		c.cacheLock.RUnlock()
-		return gvr, nil
+// dryRunOptions returns the DryRun value of create, patch and delete options:
+// server-side dry run of all stages when dryRun is set, nothing otherwise.
+func dryRunOptions(dryRun bool) []string {
+	if dryRun {
+		return []string{metav1.DryRunAll}
 	}
-	c.cacheLock.RUnlock()
-
-	// Cache miss; fetch from discovery client
-	resourceLists, err := c.discoveryClient.ServerPreferredResources()
-	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
-		return nil, fmt.Errorf("failed to retrieve API resources: %w", err)
-	}
-
-	for _, resourceList := range resourceLists {
-		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
-		if err != nil {
-			continue
-		}
-		for _, resource := range resourceList.APIResources {
-			if resource.Kind == kind {
-				gvr := &schema.GroupVersionResource{
-					Group:    gv.Group,
-					Version:  gv.Version,
-					Resource: resource.Name,
-				}
-				c.cacheLock.Lock()
-				c.apiResourceCache[kind] = gvr
-				c.cacheLock.Unlock()
-				return gvr, nil
-			}
-		}
-	}
-
-	return nil, fmt.Errorf("resource type %s not found", kind)
+	return nil
 }
 
-// DescribeResource retrieves detailed information about a specific resource, similar to GetResource.
-// It uses the dynamic client to fetch the resource by kind, name, and namespace.
-// It utilizes a cached GroupVersionResource (GVR) for efficiency.
-// Returns the unstructured content of the resource as a map, or an error.
-// Note: This function currently has the same implementation as GetResource.
-func (c *Client) DescribeResource(ctx context.Context, kind, name, namespace string) (map[string]interface{}, error) {
-	gvr, err := c.getCachedGVR(kind)
+// getCachedGVR retrieves the GroupVersionResource for a resource type, using a cache for performance.
+// See ResolveResource for the accepted forms.
+func (c *Client) getCachedGVR(kind string) (*schema.GroupVersionResource, error) {
+	info, err := c.ResolveResource(kind, "")
 	if err != nil {
 		return nil, err
 	}
-
-	var obj *unstructured.Unstructured
-	if namespace != "" {
-		obj, err = c.dynamicClient.Resource(*gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
-	} else {
-		obj, err = c.dynamicClient.Resource(*gvr).Get(ctx, name, metav1.GetOptions{})
-	}
-	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve resource: %w", err)
-	}
-
-	return obj.UnstructuredContent(), nil
+	return &info.GVR, nil
 }
 
-// GetPodsLogs retrieves the logs for a specific pod.
-// It uses the corev1 clientset to fetch logs, limiting to the last 100 lines by default.
-// If containerName is provided, it gets logs for that specific container.
-// If containerName is empty and the pod has multiple containers, it gets logs from all containers.
-// Returns the logs as a string, or an error.
-func (c *Client) GetPodsLogs(ctx context.Context, namespace, containerName, podName string) (string, error) {
-	tailLines := int64(100)
-	podLogOptions := &corev1.PodLogOptions{
-		TailLines: &tailLines,
-	}
-
-	// If container name is provided, use it
-	if containerName != "" {
-		podLogOptions.Container = containerName
-		req := c.clientset.CoreV1().Pods(namespace).GetLogs(podName, podLogOptions)
-		logs, err := req.Stream(ctx)
-		if err != nil {
-			return "", fmt.Errorf("failed to get logs for container '%s': %w", containerName, err)
-		}
-		defer logs.Close()
-
-		buf := new(bytes.Buffer)
-		if _, err := io.Copy(buf, logs); err != nil {
-			return "", fmt.Errorf("failed to read logs: %w", err)
-		}
-		return buf.String(), nil
-	}
-
-	// If no container name provided, first get the pod to check its containers
-	pod, err := c.clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+// resourceInterface returns the dynamic resource interface for a resource type.
+// The namespace is ignored for cluster-scoped resources; an empty namespace
+// addresses namespaced resources across all namespaces.
+func (c *Client) resourceInterface(kind, namespace string) (dynamic.ResourceInterface, error) {
+	info, err := c.ResolveResource(kind, "")
 	if err != nil {
-		return "", fmt.Errorf("failed to get pod details: %w", err)
-	}
-
-	// If the pod has only one container, get logs from that container
-	if len(pod.Spec.Containers) == 1 {
-		req := c.clientset.CoreV1().Pods(namespace).GetLogs(podName, podLogOptions)
-		logs, err := req.Stream(ctx)
-		if err != nil {
-			return "", fmt.Errorf("failed to get logs: %w", err)
-		}
-		defer logs.Close()
-
-		buf := new(bytes.Buffer)
-		if _, err := io.Copy(buf, logs); err != nil {
-			return "", fmt.Errorf("failed to read logs: %w", err)
-		}
-		return buf.String(), nil
+		return nil, err
 	}
-
-	// If the pod has multiple containers, get logs from each container
-	var allLogs strings.Builder
-	for _, container := range pod.Spec.Containers {
-		containerLogOptions := podLogOptions.DeepCopy()
-		containerLogOptions.Container = container.Name
-
-		req := c.clientset.CoreV1().Pods(namespace).GetLogs(podName, containerLogOptions)
-		logs, err := req.Stream(ctx)
-		if err != nil {
-			allLogs.WriteString(fmt.Sprintf("\n--- Error getting logs for container %s: %v ---\n", container.Name, err))
-			continue
-		}
-
-		allLogs.WriteString(fmt.Sprintf("\n--- Logs for container %s ---\n", container.Name))
-		buf := new(bytes.Buffer)
-		_, err = io.Copy(buf, logs)
-		logs.Close()
-
-		if err != nil {
-			allLogs.WriteString(fmt.Sprintf("Error reading logs: %v\n", err))
-		} else {
-			allLogs.WriteString(buf.String())
-		}
+	if !info.Namespaced || namespace == "" {
+		return c.dynamicClient.Resource(info.GVR), nil
 	}
-
-	return allLogs.String(), nil
+	return c.dynamicClient.Resource(info.GVR).Namespace(namespace), nil
 }
 
 // GetPodMetrics retrieves CPU and Memory metrics for a specific pod.
@@ -633,8 +578,9 @@ func (c *Client) GetIngresses(ctx context.Context, host string) ([]map[string]in
 
 // RolloutRestart restarts any Kubernetes workload with a pod template (Deployment, DaemonSet, StatefulSet, etc.).
 // It patches the spec.template.metadata.annotations with the current timestamp.
+// With dryRun set, the patch is validated server-side without being persisted.
 // Returns the patched resource content or an error if the resource doesn't support rollout restart.
-func (c *Client) RolloutRestart(ctx context.Context, kind, name, namespace string) (map[string]interface{}, error) {
+func (c *Client) RolloutRestart(ctx context.Context, kind, name, namespace string, dryRun bool) (map[string]interface{}, error) {
 	gvr, err := c.getCachedGVR(kind)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get GVR for kind %s: %w", kind, err)
@@ -647,7 +593,7 @@ func (c *Client) RolloutRestart(ctx context.Context, kind, name, namespace strin
 		time.Now().Format(time.RFC3339),
 	))
 
-	result, err := resource.Patch(ctx, name, types.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	result, err := resource.Patch(ctx, name, types.StrategicMergePatchType, patch, metav1.PatchOptions{DryRun: dryRunOptions(dryRun)})
 	if err != nil {
 		return nil, fmt.Errorf("failed to rollout restart %s %s/%s: %w", kind, namespace, name, err)
 	}
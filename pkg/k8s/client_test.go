@@ -0,0 +1,69 @@
+package k8s
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestCreateOrUpdateResourceJSONDryRunInMissingNamespace(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/namespaces/new":
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v1/namespaces":
+			w.WriteHeader(http.StatusCreated)
+			io.WriteString(w, `{"kind":"Namespace","apiVersion":"v1","metadata":{"name":"new"}}`)
+		default:
+			http.Error(w, "unexpected request", http.StatusMethodNotAllowed)
+		}
+	}))
+	defer server.Close()
+
+	config := &rest.Config{Host: server.URL}
+	client := newResolverClient([]*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{
+			{Name: "configmaps", SingularName: "configmap", Kind: "ConfigMap", Namespaced: true},
+		},
+	}})
+	client.clientset = kubernetes.NewForConfigOrDie(config)
+	client.dynamicClient = dynamic.NewForConfigOrDie(config)
+
+	manifest := `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings"},"data":{"mode":"debug"}}`
+	result, note, err := client.CreateOrUpdateResourceJSON(context.Background(), "new", manifest, "ConfigMap", true)
+	if err != nil {
+		t.Fatalf("CreateOrUpdateResourceJSON failed: %v", err)
+	}
+
+	want := []string{
+		"GET /api/v1/namespaces/new?",
+		"POST /api/v1/namespaces?dryRun=All",
+	}
+	if len(requests) != len(want) {
+		t.Fatalf("requests = %v; want %v", requests, want)
+	}
+	for i := range want {
+		if requests[i] != want[i] {
+			t.Errorf("request %d = %s; want %s", i, requests[i], want[i])
+		}
+	}
+	if metadata, _ := result["metadata"].(map[string]interface{}); metadata["namespace"] != "new" {
+		t.Errorf("result namespace = %v; want new", metadata["namespace"])
+	}
+	if !strings.Contains(note, "Namespace new does not exist") || !strings.Contains(note, "not validated") {
+		t.Errorf("note = %q; want it to say the object was not validated", note)
+	}
+}
@@ -0,0 +1,228 @@
+package k8s
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// Column is a named JSONPath expression evaluated against every listed
+// object, like a column of kubectl's custom-columns output.
+type Column struct {
+	Name       string
+	Expression string
+	parser     *jsonpath.JSONPath
+}
+
+// ParseColumns parses a comma-separated list of columns in kubectl
+// custom-columns syntax, such as "IMAGE:.spec.containers[*].image,NODE:.spec.nodeName".
+// The name may be left out, in which case the last field of the expression is
+// used ("status.phase" becomes "phase"). Expressions may be written with or
+// without the leading dot and surrounding braces.
+func ParseColumns(spec string) ([]Column, error) {
+	var columns []Column
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, expression, named := strings.Cut(part, ":")
+		if !named {
+			expression = name
+			name = ""
+		}
+		expression = relaxedJSONPath(strings.TrimSpace(expression))
+		if name == "" {
+			name = columnName(expression)
+		}
+
+		parser := jsonpath.New(name).AllowMissingKeys(true)
+		if err := parser.Parse(expression); err != nil {
+			return nil, fmt.Errorf("invalid field expression %q: %w", part, err)
+		}
+		columns = append(columns, Column{Name: name, Expression: expression, parser: parser})
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("no fields given")
+	}
+	return columns, nil
+}
+
+// Value evaluates the column against obj. Missing fields evaluate to nil and
+// expressions matching several values, such as ".spec.containers[*].image",
+// to a list of them.
+func (c Column) Value(obj *unstructured.Unstructured) interface{} {
+	results, err := c.parser.FindResults(obj.UnstructuredContent())
+	if err != nil {
+		return nil
+	}
+
+	var values []interface{}
+	for _, result := range results {
+		for _, value := range result {
+			if value.IsValid() && value.CanInterface() {
+				values = append(values, value.Interface())
+			}
+		}
+	}
+	switch len(values) {
+	case 0:
+		return nil
+	case 1:
+		return values[0]
+	default:
+		return values
+	}
+}
+
+// relaxedJSONPath turns "status.phase" or ".status.phase" into "{.status.phase}",
+// as kubectl does for custom columns.
+func relaxedJSONPath(expression string) string {
+	if strings.HasPrefix(expression, "{") {
+		return expression
+	}
+	if !strings.HasPrefix(expression, ".") {
+		expression = "." + expression
+	}
+	return "{" + expression + "}"
+}
+
+// columnName derives a column name from the last field of an expression.
+func columnName(expression string) string {
+	name := strings.Trim(expression, "{}")
+	if i := strings.Index(name, "["); i >= 0 {
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	if name == "" {
+		return expression
+	}
+	return name
+}
+
+// listItem summarizes a listed object. With columns, it holds the object's
+// name, kind and namespace and, under "columns", the value of every column.
+// Keeping the columns apart means their user-chosen names cannot replace the
+// kind the redaction of tool results relies on. Otherwise it holds
+// the name, kind, namespace, labels, age and controlling owner, plus the
+// kind-specific defaults of defaultColumns.
+func listItem(obj *unstructured.Unstructured, columns []Column) map[string]interface{} {
+	// The kind lets the redaction of tool results recognize Secrets
+	item := map[string]interface{}{
+		"name":      obj.GetName(),
+		"kind":      obj.GetKind(),
+		"namespace": obj.GetNamespace(),
+	}
+	if len(columns) > 0 {
+		values := make(map[string]interface{}, len(columns))
+		for _, column := range columns {
+			values[column.Name] = column.Value(obj)
+		}
+		item["columns"] = values
+		return item
+	}
+
+	item["labels"] = obj.GetLabels()
+	if created := obj.GetCreationTimestamp(); !created.IsZero() {
+		item["age"] = duration.HumanDuration(time.Since(created.Time))
+	}
+	if owner := controllerOf(obj); owner != "" {
+		item["owner"] = owner
+	}
+	for key, value := range defaultColumns(obj) {
+		item[key] = value
+	}
+	return item
+}
+
+// controllerOf returns the controlling owner of obj as "Kind/name".
+func controllerOf(obj *unstructured.Unstructured) string {
+	for _, owner := range obj.GetOwnerReferences() {
+		if owner.Controller != nil && *owner.Controller {
+			return owner.Kind + "/" + owner.Name
+		}
+	}
+	return ""
+}
+
+// defaultColumns returns the fields kubectl get shows for common kinds, such
+// as the phase, readiness, restarts and node of a Pod.
+func defaultColumns(obj *unstructured.Unstructured) map[string]interface{} {
+	content := obj.UnstructuredContent()
+	switch obj.GetKind() {
+	case "Pod":
+		containers, _, _ := unstructured.NestedSlice(content, "spec", "containers")
+		statuses, _, _ := unstructured.NestedSlice(content, "status", "containerStatuses")
+		ready, restarts := int64(0), int64(0)
+		for _, status := range statuses {
+			if status, ok := status.(map[string]interface{}); ok {
+				if isReady, _, _ := unstructured.NestedBool(status, "ready"); isReady {
+					ready++
+				}
+				count, _, _ := unstructured.NestedInt64(status, "restartCount")
+				restarts += count
+			}
+		}
+		phase, _, _ := unstructured.NestedString(content, "status", "phase")
+		node, _, _ := unstructured.NestedString(content, "spec", "nodeName")
+		return map[string]interface{}{
+			"phase":    phase,
+			"ready":    fmt.Sprintf("%d/%d", ready, len(containers)),
+			"restarts": restarts,
+			"node":     node,
+		}
+	case "Deployment":
+		replicas, found, _ := unstructured.NestedInt64(content, "spec", "replicas")
+		if !found {
+			replicas = 1
+		}
+		ready, _, _ := unstructured.NestedInt64(content, "status", "readyReplicas")
+		updated, _, _ := unstructured.NestedInt64(content, "status", "updatedReplicas")
+		available, _, _ := unstructured.NestedInt64(content, "status", "availableReplicas")
+		return map[string]interface{}{
+			"ready":     fmt.Sprintf("%d/%d", ready, replicas),
+			"updated":   updated,
+			"available": available,
+		}
+	case "Service":
+		serviceType, _, _ := unstructured.NestedString(content, "spec", "type")
+		clusterIP, _, _ := unstructured.NestedString(content, "spec", "clusterIP")
+		ports, _, _ := unstructured.NestedSlice(content, "spec", "ports")
+		return map[string]interface{}{
+			"type":      serviceType,
+			"clusterIP": clusterIP,
+			"ports":     servicePorts(ports),
+		}
+	}
+	return nil
+}
+
+// servicePorts formats service ports like kubectl: "80/TCP,443:30443/TCP".
+func servicePorts(ports []interface{}) string {
+	formatted := make([]string, 0, len(ports))
+	for _, port := range ports {
+		port, ok := port.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		number, _, _ := unstructured.NestedInt64(port, "port")
+		protocol, _, _ := unstructured.NestedString(port, "protocol")
+		if protocol == "" {
+			protocol = "TCP"
+		}
+		text := strconv.FormatInt(number, 10)
+		if nodePort, found, _ := unstructured.NestedInt64(port, "nodePort"); found && nodePort != 0 {
+			text += ":" + strconv.FormatInt(nodePort, 10)
+		}
+		formatted = append(formatted, text+"/"+protocol)
+	}
+	return strings.Join(formatted, ",")
+}
@@ -0,0 +1,79 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kubectl/pkg/describe"
+)
+
+// DescribeResource returns the human-readable description of a resource that
+// kubectl describe prints, using the describers of k8s.io/kubectl/pkg/describe.
+// Besides the object's fields, the description includes related information
+// such as its events, controlling owner, the container states and last
+// termination reasons of Pods, the ReplicaSets and conditions of Deployments
+// and the allocated resources of Nodes. Kinds without a dedicated describer,
+// such as custom resources, get the generic description of their fields and
+// events. A namespaced resource is looked up in the "default" namespace if no
+// namespace is given.
+// The describers do not take a context, so ctx is only checked before starting.
+func (c *Client) DescribeResource(ctx context.Context, kind, name, namespace string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	info, err := c.ResolveResource(kind, "")
+	if err != nil {
+		return "", err
+	}
+
+	describer, err := c.describerFor(info)
+	if err != nil {
+		return "", err
+	}
+
+	if !info.Namespaced {
+		namespace = ""
+	} else if namespace == "" {
+		namespace = "default"
+	}
+
+	description, err := describer.Describe(namespace, name, describe.DescriberSettings{
+		ShowEvents: true,
+		ChunkSize:  describeChunkSize,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe resource: %w", err)
+	}
+	return description, nil
+}
+
+// describeChunkSize is the page size describers use to list related objects,
+// such as events, as kubectl describe does.
+const describeChunkSize = 500
+
+// describerFor returns the kubectl describer of a resource, falling back to
+// the generic describer for kinds without a dedicated one.
+func (c *Client) describerFor(info *ResourceInfo) (describe.ResourceDescriber, error) {
+	groupKind := schema.GroupKind{Group: info.GVR.Group, Kind: info.Kind}
+	if describer, ok := describe.DescriberFor(groupKind, c.restConfig); ok {
+		return describer, nil
+	}
+
+	scope := meta.RESTScopeRoot
+	if info.Namespaced {
+		scope = meta.RESTScopeNamespace
+	}
+	mapping := &meta.RESTMapping{
+		Resource:         info.GVR,
+		GroupVersionKind: info.GVR.GroupVersion().WithKind(info.Kind),
+		Scope:            scope,
+	}
+	describer, ok := describe.GenericDescriberFor(mapping, c.restConfig)
+	if !ok {
+		return nil, fmt.Errorf("no describer available for %s", info.String())
+	}
+	return describer, nil
+}
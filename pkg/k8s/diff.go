@@ -0,0 +1,131 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/pmezard/go-difflib/difflib"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// ResourceDiff describes how applying a manifest would change the live object.
+type ResourceDiff struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	// Exists is false when applying the manifest would create the object.
+	Exists bool `json:"exists"`
+	// Changed is false when applying the manifest would be a no-op.
+	Changed bool `json:"changed"`
+	// Diff is a unified diff of the live and the proposed object in YAML.
+	Diff string `json:"diff"`
+	// Conflicts are the fields owned by other field managers that would make
+	// the apply fail unless forced. The diff then shows a forced apply.
+	Conflicts []FieldConflict `json:"conflicts,omitempty"`
+}
+
+// DiffResource previews applying a YAML or JSON manifest.
+// It fetches the live object, runs a server-side dry-run apply of the manifest,
+// strips managed fields, status and other server-maintained noise from both,
+// and returns a unified YAML diff between them.
+// The dry run is not forced, so fields owned by other field managers are
+// reported as conflicts, as applyResource would reject them; the diff is then
+// computed from a forced dry run to show what forcing the apply would change.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - namespace: Target namespace (overrides the manifest namespace if provided)
+//   - manifest: YAML or JSON manifest of a single Kubernetes resource
+//   - kind: Resource kind (optional, inferred from the manifest if empty)
+//   - fieldManager: Field manager the apply would use (defaults to DefaultFieldManager)
+func (c *Client) DiffResource(ctx context.Context, namespace, manifest, kind, fieldManager string) (*ResourceDiff, error) {
+	obj, resource, err := c.applyTarget(namespace, manifest, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	exists := true
+	live, err := resource.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		exists = false
+		live = nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get live resource: %w", err)
+	}
+
+	options := ApplyOptions{FieldManager: fieldManager, DryRun: true}
+	proposed, err := serverSideApply(ctx, resource, obj, options)
+	conflicts := fieldConflicts(err)
+	if len(conflicts) > 0 {
+		options.Force = true
+		proposed, err = serverSideApply(ctx, resource, obj, options)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to dry-run apply manifest: %w", err)
+	}
+
+	liveYAML, err := diffableYAML(live)
+	if err != nil {
+		return nil, err
+	}
+	proposedYAML, err := diffableYAML(proposed)
+	if err != nil {
+		return nil, err
+	}
+
+	objectPath := path.Join(obj.GetKind(), obj.GetNamespace(), obj.GetName())
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(liveYAML),
+		B:        splitLines(proposedYAML),
+		FromFile: "live/" + objectPath,
+		ToFile:   "proposed/" + objectPath,
+		Context:  3,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute diff: %w", err)
+	}
+
+	return &ResourceDiff{
+		Kind:      obj.GetKind(),
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
+		Exists:    exists,
+		Changed:   diff != "",
+		Diff:      diff,
+		Conflicts: conflicts,
+	}, nil
+}
+
+// diffableYAML renders an object as YAML without the fields the API server
+// maintains on its own, which would otherwise show up in every diff.
+// A nil object renders as an empty document.
+func diffableYAML(obj *unstructured.Unstructured) (string, error) {
+	if obj == nil {
+		return "", nil
+	}
+
+	content := obj.DeepCopy().UnstructuredContent()
+	unstructured.RemoveNestedField(content, "status")
+	for _, field := range []string{"managedFields", "resourceVersion", "generation", "uid", "creationTimestamp", "selfLink"} {
+		unstructured.RemoveNestedField(content, "metadata", field)
+	}
+
+	out, err := yaml.Marshal(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to render resource as YAML: %w", err)
+	}
+	return string(out), nil
+}
+
+// splitLines splits text into lines for difflib, treating empty text as no lines.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return difflib.SplitLines(text)
+}
@@ -0,0 +1,111 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+const diffManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+spec:
+  replicas: 3
+`
+
+// newDiffServer serves a Deployment with 2 replicas whose replicas field is
+// owned by another field manager if conflicting is set, and answers apply
+// patches with the applied object, or with a conflict unless forced.
+func newDiffServer(t *testing.T, conflicting bool) (*httptest.Server, *[]string) {
+	var applies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			io.WriteString(w, `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"name":"web","namespace":"default"},"spec":{"replicas":2}}`)
+		case http.MethodPatch:
+			force := r.URL.Query().Get("force")
+			applies = append(applies, "force="+force)
+			if r.URL.Query().Get("dryRun") != "All" {
+				t.Errorf("apply was not a dry run: %s", r.URL.RawQuery)
+			}
+			if conflicting && force != "true" {
+				w.WriteHeader(http.StatusConflict)
+				json.NewEncoder(w).Encode(metav1.Status{
+					TypeMeta: metav1.TypeMeta{Kind: "Status", APIVersion: "v1"},
+					Status:   metav1.StatusFailure,
+					Reason:   metav1.StatusReasonConflict,
+					Code:     http.StatusConflict,
+					Message:  `Apply failed with 1 conflict: conflict with "kubectl-edit" using apps/v1: .spec.replicas`,
+					Details: &metav1.StatusDetails{Causes: []metav1.StatusCause{{
+						Type:    metav1.CauseTypeFieldManagerConflict,
+						Message: `conflict with "kubectl-edit" using apps/v1`,
+						Field:   ".spec.replicas",
+					}}},
+				})
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			w.Write(body)
+		default:
+			http.Error(w, "unexpected request", http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &applies
+}
+
+func TestDiffResource(t *testing.T) {
+	tests := []struct {
+		name        string
+		conflicting bool
+		wantApplies []string
+		wantManager string
+	}{
+		{name: "no conflicts", wantApplies: []string{"force=false"}},
+		{name: "conflicts are reported", conflicting: true, wantApplies: []string{"force=false", "force=true"}, wantManager: "kubectl-edit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, applies := newDiffServer(t, tt.conflicting)
+			dynamicClient, err := dynamic.NewForConfig(&rest.Config{Host: server.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+			client := newResolverClient([]*metav1.APIResourceList{{
+				GroupVersion: "apps/v1",
+				APIResources: []metav1.APIResource{
+					{Name: "deployments", SingularName: "deployment", Kind: "Deployment", Namespaced: true},
+				},
+			}})
+			client.dynamicClient = dynamicClient
+
+			diff, err := client.DiffResource(context.Background(), "", diffManifest, "", "")
+			if err != nil {
+				t.Fatalf("DiffResource failed: %v", err)
+			}
+			if strings.Join(*applies, ",") != strings.Join(tt.wantApplies, ",") {
+				t.Errorf("applies = %v; want %v", *applies, tt.wantApplies)
+			}
+			if !diff.Changed || !strings.Contains(diff.Diff, "+  replicas: 3") {
+				t.Errorf("diff does not show the replicas change:\n%s", diff.Diff)
+			}
+			switch {
+			case tt.wantManager == "" && len(diff.Conflicts) != 0:
+				t.Errorf("conflicts = %v; want none", diff.Conflicts)
+			case tt.wantManager != "" && (len(diff.Conflicts) != 1 || diff.Conflicts[0].Field != ".spec.replicas" || diff.Conflicts[0].Manager != tt.wantManager):
+				t.Errorf("conflicts = %v; want .spec.replicas owned by %s", diff.Conflicts, tt.wantManager)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package k8s
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"k8s.io/client-go/discovery"
+)
+
+// DefaultDiscoveryTTL is how long discovery information is cached unless
+// another TTL is configured.
+const DefaultDiscoveryTTL = 10 * time.Minute
+
+// minDiscoveryRefreshInterval limits how often a resource type that cannot be
+// resolved triggers a refresh, so repeated typos do not re-run discovery.
+const minDiscoveryRefreshInterval = 5 * time.Second
+
+// DiscoverySummary describes the API resources found by a discovery refresh.
+type DiscoverySummary struct {
+	Groups      int       `json:"groups"`
+	Resources   int       `json:"resources"`
+	RefreshedAt time.Time `json:"refreshedAt"`
+	// FailedGroups lists the group versions that could not be discovered,
+	// typically aggregated APIs whose backing service is unavailable.
+	FailedGroups []string `json:"failedGroups,omitempty"`
+}
+
+// SetDiscoveryTTL sets how long discovery information is cached before it is
+// fetched again. A TTL of zero keeps it until InvalidateDiscovery is called.
+func (c *Client) SetDiscoveryTTL(ttl time.Duration) {
+	c.cacheLock.Lock()
+	defer c.cacheLock.Unlock()
+	c.discoveryTTL = ttl
+}
+
+// InvalidateDiscovery drops the cached discovery information and resolved
+// resource types, so that CustomResourceDefinitions installed or removed since
+// they were fetched are seen on the next lookup.
+func (c *Client) InvalidateDiscovery() {
+	c.cacheLock.Lock()
+	defer c.cacheLock.Unlock()
+	c.invalidateDiscoveryLocked()
+}
+
+// RefreshDiscovery invalidates the cached discovery information, fetches it
+// again and summarizes what was found.
+func (c *Client) RefreshDiscovery() (*DiscoverySummary, error) {
+	c.InvalidateDiscovery()
+
+	groups, resourceLists, err := c.discoveryClient.ServerGroupsAndResources()
+	summary := &DiscoverySummary{Groups: len(groups), RefreshedAt: time.Now()}
+	if err != nil {
+		var failed *discovery.ErrGroupDiscoveryFailed
+		if !errors.As(err, &failed) {
+			return nil, fmt.Errorf("failed to retrieve API resources: %w", err)
+		}
+		for groupVersion := range failed.Groups {
+			summary.FailedGroups = append(summary.FailedGroups, groupVersion.String())
+		}
+		sort.Strings(summary.FailedGroups)
+	}
+	for _, resourceList := range resourceLists {
+		summary.Resources += len(resourceList.APIResources)
+	}
+	return summary, nil
+}
+
+// expireDiscovery invalidates the cached discovery information once it is
+// older than the TTL. It is called before every discovery lookup.
+func (c *Client) expireDiscovery() {
+	c.cacheLock.Lock()
+	defer c.cacheLock.Unlock()
+	if c.discoveryTTL > 0 && time.Since(c.discoveryRefreshed) > c.discoveryTTL {
+		c.invalidateDiscoveryLocked()
+	}
+}
+
+// refreshDiscoveryOnMiss invalidates the cached discovery information after a
+// lookup found nothing, unless it was fetched only moments ago. It reports
+// whether the lookup is worth retrying.
+func (c *Client) refreshDiscoveryOnMiss() bool {
+	c.cacheLock.Lock()
+	defer c.cacheLock.Unlock()
+	if time.Since(c.discoveryRefreshed) < minDiscoveryRefreshInterval {
+		return false
+	}
+	c.invalidateDiscoveryLocked()
+	return true
+}
+
+// invalidateDiscoveryLocked drops the cached discovery information. The caller
+// must hold cacheLock.
+func (c *Client) invalidateDiscoveryLocked() {
+	c.discoveryClient.Invalidate()
+	c.resourceCache = make(map[string]*ResourceInfo)
+	c.discoveryRefreshed = time.Now()
+}
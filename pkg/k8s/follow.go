@@ -0,0 +1,215 @@
+package k8s
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultFollowDuration is how long FollowPodLogs follows logs unless
+// another duration is requested. MaxFollowDuration bounds the requested duration.
+const (
+	DefaultFollowDuration = 30 * time.Second
+	MaxFollowDuration     = 5 * time.Minute
+)
+
+// DefaultFollowMaxLines is the number of matching lines after which
+// FollowPodLogs stops, unless another number is requested.
+const DefaultFollowMaxLines = 500
+
+// followSummaryLines is the number of last lines kept for the summary.
+const followSummaryLines = 20
+
+// Reasons FollowPodLogs stopped following logs.
+const (
+	FollowStoppedDuration  = "duration elapsed"
+	FollowStoppedUntil     = "until pattern matched"
+	FollowStoppedMaxLines  = "line limit reached"
+	FollowStoppedEnded     = "log stream ended"
+	FollowStoppedCancelled = "request cancelled"
+)
+
+// FollowOptions select the logs FollowPodLogs follows and when it stops.
+type FollowOptions struct {
+	// LogOptions select the container and lines as for GetPodsLogs, except
+	// that a single container is followed and no lines from before the call
+	// are returned unless TailLines, SinceSeconds or SinceTime are set;
+	// Previous and LimitBytes cannot be used.
+	LogOptions
+	// Duration is how long to follow the logs, DefaultFollowDuration if zero.
+	Duration time.Duration
+	// Until, if set, stops following after the first line matching it.
+	Until *regexp.Regexp
+	// MaxLines stops following after this many matching lines,
+	// DefaultFollowMaxLines if zero.
+	MaxLines int
+}
+
+// FollowResult summarizes logs followed by FollowPodLogs.
+type FollowResult struct {
+	Pod       string
+	Container string
+	// StopReason is one of the FollowStopped reasons.
+	StopReason string
+	Elapsed    time.Duration
+	// TotalLines and MatchedLines count the lines read and matched.
+	TotalLines   int
+	MatchedLines int
+	// UntilLine is the line that matched the Until pattern.
+	UntilLine string
+	// LastLines are the last lines passed on.
+	LastLines []string
+	Filtered  bool
+	// passed counts the lines passed on, including context lines.
+	passed int
+}
+
+// String renders the summary, followed by the last lines passed on.
+func (r *FollowResult) String() string {
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("--- Followed logs of %s/%s for %s: %s ---\n", r.Pod, r.Container, r.Elapsed.Round(100*time.Millisecond), r.StopReason))
+	if r.Filtered {
+		out.WriteString(fmt.Sprintf("%d of %d lines matched\n", r.MatchedLines, r.TotalLines))
+	} else {
+		out.WriteString(fmt.Sprintf("%d lines\n", r.TotalLines))
+	}
+	if r.UntilLine != "" {
+		out.WriteString(fmt.Sprintf("Until pattern matched: %s\n", r.UntilLine))
+	}
+	if len(r.LastLines) > 0 {
+		if len(r.LastLines) < r.passed {
+			out.WriteString(fmt.Sprintf("--- Last %d lines ---\n", len(r.LastLines)))
+		} else {
+			out.WriteString("--- Lines ---\n")
+		}
+		for _, line := range r.LastLines {
+			out.WriteString(line + "\n")
+		}
+	}
+	return out.String()
+}
+
+// FollowPodLogs follows the logs of a container, like kubectl logs -f, and
+// calls onLine with every line selected by options.Filter, together with the
+// context lines around it, as it arrives. It stops when options.Duration has
+// elapsed, a line matches options.Until, options.MaxLines lines matched, the
+// container stops, or ctx is cancelled, and returns a summary.
+// If options.Container is empty, the pod must have a single app container.
+func (c *Client) FollowPodLogs(ctx context.Context, namespace, podName string, options FollowOptions, onLine func(line string)) (*FollowResult, error) {
+	if options.Previous {
+		return nil, fmt.Errorf("the logs of a previous container instance cannot be followed")
+	}
+	if options.LimitBytes != nil {
+		return nil, fmt.Errorf("limitBytes cannot be used when following logs")
+	}
+	if options.Duration < 0 || options.Duration > MaxFollowDuration {
+		return nil, fmt.Errorf("duration must be between 0 and %s", MaxFollowDuration)
+	}
+	if options.Duration == 0 {
+		options.Duration = DefaultFollowDuration
+	}
+	if options.MaxLines < 0 {
+		return nil, fmt.Errorf("maxLines must not be negative")
+	}
+	if options.MaxLines == 0 {
+		options.MaxLines = DefaultFollowMaxLines
+	}
+
+	container := options.Container
+	if container == "" {
+		pod, err := c.clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pod details: %w", err)
+		}
+		containers := options.logContainers(pod)
+		if len(containers) != 1 {
+			return nil, fmt.Errorf("pod %s has %d containers, a containerName must be given: %s", podName, len(containers), strings.Join(containers, ", "))
+		}
+		container = containers[0]
+	}
+
+	podLogOptions, err := options.podLogOptions(container, 0)
+	if err != nil {
+		return nil, err
+	}
+	// Only new lines are followed unless earlier ones are asked for
+	if options.TailLines == 0 && options.SinceSeconds == nil && options.SinceTime == nil {
+		var tailLines int64
+		podLogOptions.TailLines = &tailLines
+	}
+	podLogOptions.Follow = true
+
+	followCtx, cancel := context.WithTimeout(ctx, options.Duration)
+	defer cancel()
+
+	start := time.Now()
+	logs, err := c.clientset.CoreV1().Pods(namespace).GetLogs(podName, podLogOptions).Stream(followCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to follow logs: %w", err)
+	}
+	defer logs.Close()
+
+	result := &FollowResult{Pod: podName, Container: container, Filtered: options.Filter != nil}
+	selection := &logSelection{filter: options.Filter}
+	pass := func() {
+		for _, line := range selection.lines {
+			onLine(line.text)
+			result.passed++
+			result.LastLines = append(result.LastLines, line.text)
+			if len(result.LastLines) > followSummaryLines {
+				result.LastLines = result.LastLines[1:]
+			}
+		}
+		selection.lines = selection.lines[:0]
+		selection.kept = 0
+	}
+
+	reader := bufio.NewReader(logs)
+	for result.StopReason == "" {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			line = strings.TrimSuffix(line, "\n")
+			matchText := line
+			if podLogOptions.Timestamps {
+				matchText = stripTimestamp(line)
+			}
+			selection.add(line, matchText)
+
+			// The line the logs were waited for is passed on even if it is filtered out
+			if options.Until != nil && options.Until.MatchString(matchText) {
+				if len(selection.lines) == 0 || selection.lines[len(selection.lines)-1].number != selection.total {
+					selection.lines = append(selection.lines, selectedLine{number: selection.total, text: line})
+				}
+				result.UntilLine = line
+				result.StopReason = FollowStoppedUntil
+			}
+			pass()
+			if result.StopReason == "" && selection.matched >= options.MaxLines {
+				result.StopReason = FollowStoppedMaxLines
+			}
+		}
+
+		switch {
+		case result.StopReason != "":
+		case ctx.Err() != nil:
+			result.StopReason = FollowStoppedCancelled
+		case followCtx.Err() != nil:
+			result.StopReason = FollowStoppedDuration
+		case err == io.EOF:
+			result.StopReason = FollowStoppedEnded
+		case err != nil:
+			return nil, fmt.Errorf("failed to read logs: %w", err)
+		}
+	}
+
+	result.Elapsed = time.Since(start)
+	result.TotalLines = selection.total
+	result.MatchedLines = selection.matched
+	return result, nil
+}
@@ -0,0 +1,108 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ObjectReference identifies a Kubernetes object in impact summaries.
+type ObjectReference struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// DeletionImpact summarizes what deleting an object would remove.
+type DeletionImpact struct {
+	Resource ObjectReference `json:"resource"`
+	// Dependents are the objects that the garbage collector would delete
+	// along with the resource, found by following owner references.
+	Dependents []ObjectReference `json:"dependents,omitempty"`
+	// Notes describe anything the summary could not cover.
+	Notes []string `json:"notes,omitempty"`
+}
+
+// dependentResources are the resources searched for objects owned, directly
+// or transitively, by a deleted object.
+var dependentResources = []schema.GroupVersionResource{
+	{Group: "apps", Version: "v1", Resource: "replicasets"},
+	{Group: "apps", Version: "v1", Resource: "controllerrevisions"},
+	{Group: "batch", Version: "v1", Resource: "jobs"},
+	{Group: "", Version: "v1", Resource: "pods"},
+	{Group: "discovery.k8s.io", Version: "v1", Resource: "endpointslices"},
+}
+
+// DeletionImpact returns the object that deleteResource would remove and the
+// dependents that would be garbage collected with it, such as the ReplicaSets
+// and Pods owned by a Deployment.
+func (c *Client) DeletionImpact(ctx context.Context, kind, name, namespace string) (*DeletionImpact, error) {
+	resource, err := c.resourceInterface(kind, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	obj, err := resource.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve resource: %w", err)
+	}
+
+	impact := &DeletionImpact{
+		Resource: ObjectReference{Kind: obj.GetKind(), Name: obj.GetName(), Namespace: obj.GetNamespace()},
+	}
+
+	if obj.GetKind() == "Namespace" {
+		impact.Notes = append(impact.Notes, fmt.Sprintf("Deleting namespace %s deletes every resource in it", name))
+		return impact, nil
+	}
+	if obj.GetNamespace() == "" {
+		impact.Notes = append(impact.Notes, "Dependents of cluster-scoped resources are not listed")
+		return impact, nil
+	}
+
+	// Index candidate dependents by owner UID, then walk down from the object
+	children := make(map[types.UID][]ObjectReference)
+	uids := make(map[ObjectReference]types.UID)
+	for _, dependentGVR := range dependentResources {
+		list, err := c.dynamicClient.Resource(dependentGVR).Namespace(obj.GetNamespace()).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			impact.Notes = append(impact.Notes, fmt.Sprintf("Could not list %s: %v", dependentGVR.Resource, err))
+			continue
+		}
+		for _, item := range list.Items {
+			ref := ObjectReference{Kind: item.GetKind(), Name: item.GetName(), Namespace: item.GetNamespace()}
+			uids[ref] = item.GetUID()
+			for _, owner := range item.GetOwnerReferences() {
+				children[owner.UID] = append(children[owner.UID], ref)
+			}
+		}
+	}
+
+	queue := []types.UID{obj.GetUID()}
+	seen := map[types.UID]bool{obj.GetUID(): true}
+	for len(queue) > 0 {
+		uid := queue[0]
+		queue = queue[1:]
+		for _, child := range children[uid] {
+			childUID := uids[child]
+			if seen[childUID] {
+				continue
+			}
+			seen[childUID] = true
+			impact.Dependents = append(impact.Dependents, child)
+			queue = append(queue, childUID)
+		}
+	}
+
+	sort.Slice(impact.Dependents, func(i, j int) bool {
+		if impact.Dependents[i].Kind != impact.Dependents[j].Kind {
+			return impact.Dependents[i].Kind < impact.Dependents[j].Kind
+		}
+		return impact.Dependents[i].Name < impact.Dependents[j].Name
+	})
+	return impact, nil
+}
@@ -0,0 +1,267 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Log levels, from least to most severe.
+const (
+	LogLevelDebug = iota + 1
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+	LogLevelFatal
+)
+
+// logLevelNames maps the level names used by common logging libraries to levels.
+var logLevelNames = map[string]int{
+	"trace":    LogLevelDebug,
+	"debug":    LogLevelDebug,
+	"info":     LogLevelInfo,
+	"notice":   LogLevelInfo,
+	"warn":     LogLevelWarn,
+	"warning":  LogLevelWarn,
+	"error":    LogLevelError,
+	"err":      LogLevelError,
+	"fatal":    LogLevelFatal,
+	"critical": LogLevelFatal,
+	"crit":     LogLevelFatal,
+	"panic":    LogLevelFatal,
+	"alert":    LogLevelFatal,
+	"emerg":    LogLevelFatal,
+}
+
+// jsonLevelKeys are the keys holding the level of JSON log lines.
+var jsonLevelKeys = []string{"level", "severity", "lvl", "log.level", "loglevel"}
+
+var (
+	// logfmtLevel matches the level of logfmt lines, such as level=error.
+	logfmtLevel = regexp.MustCompile(`(?:^|\s)(?:level|lvl|severity)="?([A-Za-z]+)`)
+	// klogLevel matches the header of klog and glog lines, such as E0101 12:00:00.000000.
+	klogLevel = regexp.MustCompile(`^([IWEF])\d{4} \d{2}:\d{2}:\d{2}`)
+	// plainLevel matches an upper-case level name in plain text lines, such as
+	// "2025-01-01 12:00:00 ERROR ..." or "[WARN] ...".
+	plainLevel = regexp.MustCompile(`\b(TRACE|DEBUG|INFO|NOTICE|WARN|WARNING|ERROR|FATAL|CRITICAL|PANIC)\b`)
+)
+
+// LogFilter selects the lines of logs to return while they are streamed.
+type LogFilter struct {
+	// Pattern, if set, selects the lines matching it.
+	Pattern *regexp.Regexp
+	// Invert selects the lines that do not match Pattern instead.
+	Invert bool
+	// MinLevel, if set, selects the lines logged at this level or above.
+	// Lines whose level cannot be recognized are dropped.
+	MinLevel int
+	// Context is the number of lines kept before and after every selected line.
+	Context int
+}
+
+// NewLogFilter creates a filter from the arguments of the log tools. It
+// returns nil if neither grep nor level is set, since all lines are returned then.
+func NewLogFilter(grep string, invert bool, level string, context int) (*LogFilter, error) {
+	if grep == "" && level == "" {
+		if invert || context != 0 {
+			return nil, fmt.Errorf("invert and context lines require grep or level")
+		}
+		return nil, nil
+	}
+	if context < 0 {
+		return nil, fmt.Errorf("context lines must not be negative")
+	}
+
+	filter := &LogFilter{Invert: invert, Context: context}
+	if grep != "" {
+		pattern, err := regexp.Compile(grep)
+		if err != nil {
+			return nil, fmt.Errorf("invalid grep pattern: %w", err)
+		}
+		filter.Pattern = pattern
+	}
+	if level != "" {
+		minLevel, ok := logLevelNames[strings.ToLower(level)]
+		if !ok {
+			return nil, fmt.Errorf("unknown log level %q: must be one of debug, info, warn, error, fatal", level)
+		}
+		filter.MinLevel = minLevel
+	}
+	return filter, nil
+}
+
+// Match reports whether a log line is selected by the filter.
+func (f *LogFilter) Match(line string) bool {
+	if f.Pattern != nil && f.Pattern.MatchString(line) == f.Invert {
+		return false
+	}
+	if f.MinLevel > 0 && LogLevel(line) < f.MinLevel {
+		return false
+	}
+	return true
+}
+
+// LogLevel returns the level of a log line in one of the common formats:
+// JSON with a level or severity field, logfmt, klog, or plain text with an
+// upper-case level name. It returns 0 if the level cannot be recognized.
+func LogLevel(line string) int {
+	if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "{") {
+		var fields map[string]interface{}
+		if err := json.Unmarshal([]byte(trimmed), &fields); err == nil {
+			for _, key := range jsonLevelKeys {
+				if level := jsonLogLevel(fields[key]); level > 0 {
+					return level
+				}
+			}
+			return 0
+		}
+	}
+	if match := klogLevel.FindStringSubmatch(line); match != nil {
+		return map[string]int{"I": LogLevelInfo, "W": LogLevelWarn, "E": LogLevelError, "F": LogLevelFatal}[match[1]]
+	}
+	if match := logfmtLevel.FindStringSubmatch(line); match != nil {
+		if level, ok := logLevelNames[strings.ToLower(match[1])]; ok {
+			return level
+		}
+	}
+	if match := plainLevel.FindString(line); match != "" {
+		return logLevelNames[strings.ToLower(match)]
+	}
+	return 0
+}
+
+// jsonLogLevel returns the level of a JSON level field: a name, or a number
+// as logged by pino and bunyan (10 trace, 20 debug, ..., 60 fatal).
+func jsonLogLevel(value interface{}) int {
+	switch v := value.(type) {
+	case string:
+		return logLevelNames[strings.ToLower(v)]
+	case float64:
+		switch {
+		case v >= 60:
+			return LogLevelFatal
+		case v >= 50:
+			return LogLevelError
+		case v >= 40:
+			return LogLevelWarn
+		case v >= 30:
+			return LogLevelInfo
+		case v > 0:
+			return LogLevelDebug
+		}
+	}
+	return 0
+}
+
+// selectedLine is a log line kept by a logSelection.
+type selectedLine struct {
+	// number is the line's position in the streamed logs, starting at 1.
+	number int
+	text   string
+	// match is false for context lines.
+	match bool
+}
+
+// logSelection collects the lines of a log stream selected by a filter,
+// with their context, counting the lines read and matched.
+type logSelection struct {
+	filter *LogFilter
+	// limit is the number of most recent matching lines kept; 0 keeps all.
+	limit   int
+	lines   []selectedLine
+	before  []selectedLine
+	after   int
+	total   int
+	matched int
+	// kept is the number of matching lines in lines.
+	kept int
+}
+
+// add processes the next line of the stream. matchText is the part of the
+// line the filter is applied to, such as the line without its timestamp.
+func (s *logSelection) add(text, matchText string) {
+	s.total++
+	line := selectedLine{number: s.total, text: text}
+
+	if s.filter == nil || s.filter.Match(matchText) {
+		s.matched++
+		line.match = true
+		s.lines = append(s.lines, s.before...)
+		s.before = s.before[:0]
+		s.lines = append(s.lines, line)
+		s.kept++
+		if s.filter != nil {
+			s.after = s.filter.Context
+		}
+		// Trim once twice the limit is kept, so that adding lines stays linear
+		if s.limit > 0 && s.kept > 2*s.limit {
+			s.trim()
+		}
+		return
+	}
+
+	if s.after > 0 {
+		s.after--
+		s.lines = append(s.lines, line)
+		return
+	}
+	if s.filter.Context > 0 {
+		if len(s.before) == s.filter.Context {
+			s.before = append(s.before[:0], s.before[1:]...)
+		}
+		s.before = append(s.before, line)
+	}
+}
+
+// trim drops the oldest lines so that at most limit matching lines remain,
+// with the context lines before the first of them.
+func (s *logSelection) trim() {
+	if s.limit <= 0 {
+		return
+	}
+	matches := 0
+	first := len(s.lines)
+	for i := len(s.lines) - 1; i >= 0; i-- {
+		if s.lines[i].match {
+			matches++
+			if matches > s.limit {
+				break
+			}
+			first = i
+		}
+	}
+	if matches <= s.limit {
+		return
+	}
+
+	start := first
+	context := 0
+	if s.filter != nil {
+		context = s.filter.Context
+	}
+	for start > 0 && context > 0 && !s.lines[start-1].match && s.lines[start-1].number >= s.lines[first].number-context {
+		start--
+		context--
+	}
+	s.lines = append([]selectedLine(nil), s.lines[start:]...)
+	s.kept = s.limit
+}
+
+// keptMatches returns the number of matching lines kept after trimming.
+func (s *logSelection) keptMatches() int {
+	return s.kept
+}
+
+// String joins the selected lines, separating lines that were not adjacent
+// in the stream with "--" like grep does.
+func (s *logSelection) String() string {
+	var out strings.Builder
+	for i, line := range s.lines {
+		if s.filter != nil && i > 0 && line.number != s.lines[i-1].number+1 {
+			out.WriteString("--\n")
+		}
+		out.WriteString(line.text + "\n")
+	}
+	return out.String()
+}
@@ -0,0 +1,237 @@
+package k8s
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultLogTailLines is the number of lines returned from the end of a
+// container's logs unless another number is requested.
+const DefaultLogTailLines = 100
+
+// LogOptions selects the logs GetPodsLogs returns. Most fields map onto
+// corev1.PodLogOptions.
+type LogOptions struct {
+	// Container selects a single container, which may also be an init or
+	// ephemeral container. If empty, the logs of all app containers are returned.
+	Container string
+	// InitContainers and EphemeralContainers add the logs of the pod's init
+	// and ephemeral containers when Container is empty.
+	InitContainers      bool
+	EphemeralContainers bool
+	// TailLines is the number of lines from the end of the logs to return.
+	// Zero means the default of the call, such as DefaultLogTailLines for
+	// GetPodsLogs, and a negative number all lines. With a Filter, zero
+	// searches all lines and keeps the default number of last matching lines.
+	TailLines int64
+	// SinceSeconds and SinceTime only return lines newer than a relative
+	// duration or a point in time. At most one of them may be set.
+	SinceSeconds *int64
+	SinceTime    *metav1.Time
+	// Previous returns the logs of the previous, terminated instance of the
+	// container, such as the one that crashed before a restart.
+	Previous bool
+	// Timestamps prefixes every line with its RFC3339 timestamp.
+	Timestamps bool
+	// LimitBytes caps the number of bytes returned per container.
+	LimitBytes *int64
+	// Filter, if set, selects the lines to return while the logs are streamed.
+	Filter *LogFilter
+}
+
+// podLogOptions converts options to the corev1.PodLogOptions of container,
+// requesting defaultTail lines unless options set TailLines or a Filter.
+func (options LogOptions) podLogOptions(container string, defaultTail int64) (*corev1.PodLogOptions, error) {
+	if options.SinceSeconds != nil && options.SinceTime != nil {
+		return nil, fmt.Errorf("only one of sinceSeconds and sinceTime may be set")
+	}
+	if options.SinceSeconds != nil && *options.SinceSeconds <= 0 {
+		return nil, fmt.Errorf("sinceSeconds must be positive")
+	}
+	if options.LimitBytes != nil && *options.LimitBytes <= 0 {
+		return nil, fmt.Errorf("limitBytes must be positive")
+	}
+
+	podLogOptions := &corev1.PodLogOptions{
+		Container:    container,
+		SinceSeconds: options.SinceSeconds,
+		SinceTime:    options.SinceTime,
+		Previous:     options.Previous,
+		Timestamps:   options.Timestamps,
+		LimitBytes:   options.LimitBytes,
+	}
+	switch {
+	case options.TailLines == 0 && options.Filter == nil:
+		tailLines := defaultTail
+		podLogOptions.TailLines = &tailLines
+	case options.TailLines > 0:
+		tailLines := options.TailLines
+		podLogOptions.TailLines = &tailLines
+	}
+	return podLogOptions, nil
+}
+
+// logContainers returns the names of the containers of pod whose logs are
+// requested by options: init containers first, then app containers, then
+// ephemeral containers.
+func (options LogOptions) logContainers(pod *corev1.Pod) []string {
+	if options.Container != "" {
+		return []string{options.Container}
+	}
+
+	var containers []string
+	if options.InitContainers {
+		for _, container := range pod.Spec.InitContainers {
+			containers = append(containers, container.Name)
+		}
+	}
+	for _, container := range pod.Spec.Containers {
+		containers = append(containers, container.Name)
+	}
+	if options.EphemeralContainers {
+		for _, container := range pod.Spec.EphemeralContainers {
+			containers = append(containers, container.Name)
+		}
+	}
+	return containers
+}
+
+// GetPodsLogs retrieves the logs for a specific pod.
+// It uses the corev1 clientset to fetch logs, limiting to the last
+// DefaultLogTailLines lines unless options request otherwise.
+// If options.Container is set, it gets logs for that specific container.
+// Otherwise it gets the logs of every container selected by options; when
+// there is more than one, each container's logs are preceded by a header
+// and errors of single containers are reported inline.
+// With options.Filter, only the selected lines are returned, followed by the
+// number of lines that matched.
+// Returns the logs as a string, or an error.
+func (c *Client) GetPodsLogs(ctx context.Context, namespace, podName string, options LogOptions) (string, error) {
+	// Validate the options before making any request
+	if _, err := options.podLogOptions("", DefaultLogTailLines); err != nil {
+		return "", err
+	}
+
+	if options.Container != "" {
+		logs, err := c.containerLogs(ctx, namespace, podName, options.Container, options, DefaultLogTailLines)
+		if err != nil {
+			return "", fmt.Errorf("failed to get logs for container '%s': %w", options.Container, err)
+		}
+		return formatContainerLogs(logs), nil
+	}
+
+	// If no container name provided, first get the pod to check its containers
+	pod, err := c.clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get pod details: %w", err)
+	}
+
+	// If the pod has only one container, get logs from that container
+	containers := options.logContainers(pod)
+	if len(containers) == 1 {
+		logs, err := c.containerLogs(ctx, namespace, podName, containers[0], options, DefaultLogTailLines)
+		if err != nil {
+			return "", fmt.Errorf("failed to get logs: %w", err)
+		}
+		return formatContainerLogs(logs), nil
+	}
+
+	// If there are several containers, get logs from each container
+	var allLogs strings.Builder
+	for _, container := range containers {
+		logs, err := c.containerLogs(ctx, namespace, podName, container, options, DefaultLogTailLines)
+		if err != nil {
+			allLogs.WriteString(fmt.Sprintf("\n--- Error getting logs for container %s: %v ---\n", container, err))
+			continue
+		}
+
+		allLogs.WriteString(fmt.Sprintf("\n--- Logs for container %s ---\n", container))
+		allLogs.WriteString(formatContainerLogs(logs))
+	}
+
+	return allLogs.String(), nil
+}
+
+// containerLogs streams the logs of a single container line by line and
+// returns the lines selected by options.Filter, or all lines without one.
+// defaultTail is the number of lines, or of matching lines with a filter,
+// returned unless options set TailLines.
+func (c *Client) containerLogs(ctx context.Context, namespace, podName, container string, options LogOptions, defaultTail int64) (*logSelection, error) {
+	podLogOptions, err := options.podLogOptions(container, defaultTail)
+	if err != nil {
+		return nil, err
+	}
+
+	selection := &logSelection{filter: options.Filter}
+	if options.Filter != nil && options.TailLines == 0 {
+		selection.limit = int(defaultTail)
+	}
+
+	err = c.streamLogs(ctx, namespace, podName, podLogOptions, func(line string) {
+		matchText := line
+		if podLogOptions.Timestamps {
+			matchText = stripTimestamp(line)
+		}
+		selection.add(line, matchText)
+	})
+	if err != nil {
+		return nil, err
+	}
+	selection.trim()
+	return selection, nil
+}
+
+// streamLogs streams the logs selected by podLogOptions and calls onLine
+// with every line, without its trailing newline, as it is read.
+func (c *Client) streamLogs(ctx context.Context, namespace, podName string, podLogOptions *corev1.PodLogOptions, onLine func(line string)) error {
+	logs, err := c.clientset.CoreV1().Pods(namespace).GetLogs(podName, podLogOptions).Stream(ctx)
+	if err != nil {
+		return err
+	}
+	defer logs.Close()
+
+	reader := bufio.NewReader(logs)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			onLine(strings.TrimSuffix(line, "\n"))
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read logs: %w", err)
+		}
+	}
+}
+
+// formatContainerLogs renders the logs of a container. Filtered logs end
+// with the number of matching lines.
+func formatContainerLogs(logs *logSelection) string {
+	if logs.filter == nil {
+		return logs.String()
+	}
+	summary := fmt.Sprintf("--- %d of %d lines matched", logs.matched, logs.total)
+	if kept := logs.keptMatches(); kept < logs.matched {
+		summary += fmt.Sprintf(", showing the last %d", kept)
+	}
+	return logs.String() + summary + " ---\n"
+}
+
+// stripTimestamp removes the RFC3339 timestamp the kubelet prefixes log lines
+// with when timestamps are requested.
+func stripTimestamp(line string) string {
+	if timestamp, rest, ok := strings.Cut(line, " "); ok {
+		if _, err := time.Parse(time.RFC3339Nano, timestamp); err == nil {
+			return rest
+		}
+	}
+	return line
+}
@@ -0,0 +1,369 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultLogSummaryTailLines is the number of lines from the end of each
+// container's logs SummarizePodLogs reads unless another number is requested.
+const DefaultLogSummaryTailLines = 50000
+
+// DefaultLogSummaryTemplates is the number of most frequent templates a
+// LogSummary shows unless another number is requested.
+const DefaultLogSummaryTemplates = 20
+
+const (
+	// logTemplateSimilarity is the fraction of tokens a line must share with
+	// a template to be added to it, like Drain's similarity threshold.
+	logTemplateSimilarity = 0.4
+	// maxLogTemplatesPerGroup bounds the templates of lines with the same
+	// number of tokens and first token. Further lines are added to the most
+	// similar template, so that logs of unique lines cannot exhaust memory.
+	maxLogTemplatesPerGroup = 100
+	// maxLogExampleLength is the length templates and examples are truncated to.
+	maxLogExampleLength = 300
+)
+
+// logTemplateWildcard replaces the tokens that differ between the lines of a template.
+const logTemplateWildcard = "<*>"
+
+// logVariables replace the variable parts of log lines, in this order, before
+// lines are clustered.
+var logVariables = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?`), "<TS>"},
+	{regexp.MustCompile(`\b\d{2}:\d{2}:\d{2}(?:\.\d+)?\b`), "<TS>"},
+	{regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), "<UUID>"},
+	{regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}(?::\d+)?\b`), "<IP>"},
+	{regexp.MustCompile(`(?i)\b(?:[0-9a-f]{1,4}:){7}[0-9a-f]{1,4}\b`), "<IP>"},
+	{regexp.MustCompile(`\b(?:0x[0-9a-fA-F]+|[0-9a-f]*(?:\d[0-9a-f]*[a-f]|[a-f][0-9a-f]*\d)[0-9a-f]*)\b`), "<HEX>"},
+	{regexp.MustCompile(`\b\d+(?:\.\d+)?`), "<NUM>"},
+}
+
+// LogTemplate is a group of similar log lines, such as the lines logged by
+// the same statement, with their variable parts replaced.
+type LogTemplate struct {
+	// Template is the common form of the lines: variables such as numbers,
+	// UUIDs, IP addresses and timestamps are replaced by <NUM>, <UUID>, <IP>
+	// and <TS>, and other tokens that differ between lines by <*>.
+	Template string
+	Count    int
+	// First and Last are the times the first and last line were logged at.
+	First time.Time
+	Last  time.Time
+	// Example is the first line of the template.
+	Example string
+	// Containers lists the containers that logged the lines.
+	Containers []string
+}
+
+// LogSummary summarizes the logs of a pod as the templates of its lines.
+type LogSummary struct {
+	Pod        string
+	Containers []string
+	// TotalLines counts the non-empty lines read. With a filter,
+	// MatchedLines counts the lines that matched it and were summarized.
+	TotalLines   int
+	MatchedLines int
+	Filtered     bool
+	// Templates holds the most frequent templates, ordered by count.
+	// TotalTemplates counts all templates.
+	Templates      []LogTemplate
+	TotalTemplates int
+	// Errors lists the containers whose logs could not be fetched, as
+	// "container: error".
+	Errors []string
+}
+
+// String renders the summary: a header with the number of lines and
+// templates, then every template with its count, the times of its first and
+// last line, and an example.
+func (s *LogSummary) String() string {
+	var out strings.Builder
+	lines := s.TotalLines
+	containers := strings.Join(s.Containers, ", ")
+	if s.Filtered {
+		lines = s.MatchedLines
+		out.WriteString(fmt.Sprintf("--- %d of %d lines of pod %s (%s) matched,", lines, s.TotalLines, s.Pod, containers))
+	} else {
+		out.WriteString(fmt.Sprintf("--- %d lines of pod %s (%s)", lines, s.Pod, containers))
+	}
+	out.WriteString(fmt.Sprintf(" in %d templates", s.TotalTemplates))
+	if len(s.Templates) < s.TotalTemplates {
+		out.WriteString(fmt.Sprintf(", showing the top %d", len(s.Templates)))
+	}
+	out.WriteString(" ---\n")
+
+	shown := 0
+	for _, template := range s.Templates {
+		shown += template.Count
+		out.WriteString(fmt.Sprintf("[%dx", template.Count))
+		if len(s.Containers) > 1 {
+			out.WriteString(" " + strings.Join(template.Containers, ","))
+		}
+		out.WriteString("]")
+		if !template.First.IsZero() {
+			out.WriteString(" " + template.First.Format(time.RFC3339))
+			if !template.Last.Equal(template.First) {
+				out.WriteString(" .. " + template.Last.Format(time.RFC3339))
+			}
+		}
+		out.WriteString("\n  " + template.Template + "\n")
+		if template.Example != template.Template {
+			out.WriteString("  e.g. " + template.Example + "\n")
+		}
+	}
+	if omitted := s.TotalTemplates - len(s.Templates); omitted > 0 {
+		out.WriteString(fmt.Sprintf("--- %d more templates with %d lines ---\n", omitted, lines-shown))
+	}
+
+	if len(s.Errors) > 0 {
+		out.WriteString("--- Errors ---\n")
+		for _, err := range s.Errors {
+			out.WriteString(err + "\n")
+		}
+	}
+	return out.String()
+}
+
+// SummarizePodLogs reads the logs of a pod and clusters their lines into
+// templates, like the Drain log parser, so that large logs can be understood
+// from their most frequent kinds of lines. options select the containers and
+// lines as for GetPodsLogs, except that TailLines defaults to
+// DefaultLogSummaryTailLines and options.Filter selects the lines to
+// summarize without context lines. The lines of all selected containers are
+// summarized together; errors of single containers are reported in the
+// summary. maxTemplates is the number of most frequent templates returned,
+// DefaultLogSummaryTemplates if zero.
+func (c *Client) SummarizePodLogs(ctx context.Context, namespace, podName string, options LogOptions, maxTemplates int) (*LogSummary, error) {
+	// Timestamps are needed for the first and last time of every template
+	options.Timestamps = true
+	if _, err := options.podLogOptions("", DefaultLogSummaryTailLines); err != nil {
+		return nil, err
+	}
+	if options.Filter != nil && options.Filter.Context > 0 {
+		return nil, fmt.Errorf("context lines cannot be used when summarizing logs")
+	}
+	if maxTemplates < 0 {
+		return nil, fmt.Errorf("maxTemplates must not be negative")
+	}
+	if maxTemplates == 0 {
+		maxTemplates = DefaultLogSummaryTemplates
+	}
+
+	containers := []string{options.Container}
+	if options.Container == "" {
+		pod, err := c.clientset.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get pod details: %w", err)
+		}
+		containers = options.logContainers(pod)
+	}
+
+	// The filter is applied while summarizing, so the default number of lines
+	// is read with a filter too
+	unfiltered := options
+	unfiltered.Filter = nil
+
+	summary := &LogSummary{Pod: podName, Containers: containers, Filtered: options.Filter != nil}
+	templates := newLogTemplates()
+	for _, container := range containers {
+		podLogOptions, err := unfiltered.podLogOptions(container, DefaultLogSummaryTailLines)
+		if err != nil {
+			return nil, err
+		}
+
+		var last time.Time
+		err = c.streamLogs(ctx, namespace, podName, podLogOptions, func(line string) {
+			if strings.TrimSpace(line) == "" {
+				return
+			}
+			summary.TotalLines++
+			text := line
+			if timestamp, rest, ok := strings.Cut(line, " "); ok {
+				if parsed, err := time.Parse(time.RFC3339Nano, timestamp); err == nil {
+					text = rest
+					last = parsed
+				}
+			}
+			if options.Filter != nil && !options.Filter.Match(text) {
+				return
+			}
+			summary.MatchedLines++
+			templates.add(text, container, last)
+		})
+		if err != nil {
+			if len(containers) == 1 {
+				return nil, fmt.Errorf("failed to get logs: %w", err)
+			}
+			summary.Errors = append(summary.Errors, fmt.Sprintf("%s: %v", container, err))
+		}
+	}
+
+	summary.Templates, summary.TotalTemplates = templates.top(maxTemplates)
+	return summary, nil
+}
+
+// logTemplates clusters log lines into templates, following the Drain
+// algorithm: lines are grouped by their number of tokens and first token,
+// and within a group added to the most similar template, whose differing
+// tokens become wildcards.
+type logTemplates struct {
+	groups map[string][]*logCluster
+	all    []*logCluster
+}
+
+// logCluster is a template being built from the lines added to it.
+type logCluster struct {
+	tokens      []string
+	count       int
+	first, last time.Time
+	example     string
+	containers  []string
+}
+
+func newLogTemplates() *logTemplates {
+	return &logTemplates{groups: make(map[string][]*logCluster)}
+}
+
+// add adds a line, logged by container at time, to the most similar template
+// or to a new one.
+func (t *logTemplates) add(line, container string, at time.Time) {
+	tokens := logTokens(maskLogVariables(line))
+	if len(tokens) == 0 {
+		return
+	}
+
+	// Like Drain, lines starting with a variable are grouped by their length only
+	first := tokens[0]
+	if strings.ContainsAny(first, "0123456789<") {
+		first = logTemplateWildcard
+	}
+	key := fmt.Sprintf("%d %s", len(tokens), first)
+
+	var best *logCluster
+	bestSimilarity := -1.0
+	for _, cluster := range t.groups[key] {
+		if similarity := cluster.similarity(tokens); similarity > bestSimilarity {
+			best, bestSimilarity = cluster, similarity
+		}
+	}
+	if best == nil || (bestSimilarity < logTemplateSimilarity && len(t.groups[key]) < maxLogTemplatesPerGroup) {
+		best = &logCluster{tokens: tokens, first: at, last: at, example: line}
+		t.groups[key] = append(t.groups[key], best)
+		t.all = append(t.all, best)
+	} else {
+		best.merge(tokens)
+	}
+
+	best.count++
+	// Lines of several containers are added one container after the other
+	if at.Before(best.first) {
+		best.first = at
+	}
+	if at.After(best.last) {
+		best.last = at
+	}
+	if !containsString(best.containers, container) {
+		best.containers = append(best.containers, container)
+	}
+}
+
+// similarity returns the fraction of tokens equal to the template's tokens.
+// Wildcards do not count, so lines prefer templates with fewer of them.
+func (c *logCluster) similarity(tokens []string) float64 {
+	equal := 0
+	for i, token := range tokens {
+		if c.tokens[i] == token && token != logTemplateWildcard {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(tokens))
+}
+
+// merge replaces the template's tokens that differ from tokens with wildcards.
+func (c *logCluster) merge(tokens []string) {
+	for i, token := range tokens {
+		if c.tokens[i] != token {
+			c.tokens[i] = logTemplateWildcard
+		}
+	}
+}
+
+// top returns the maxTemplates most frequent templates, ordered by count and
+// then by their first line, and the number of all templates.
+func (t *logTemplates) top(maxTemplates int) ([]LogTemplate, int) {
+	clusters := append([]*logCluster(nil), t.all...)
+	// t.all is in the order the templates were created, so a stable sort
+	// orders templates with the same count by their first line
+	sort.SliceStable(clusters, func(i, j int) bool { return clusters[i].count > clusters[j].count })
+	if len(clusters) > maxTemplates {
+		clusters = clusters[:maxTemplates]
+	}
+
+	templates := make([]LogTemplate, 0, len(clusters))
+	for _, cluster := range clusters {
+		templates = append(templates, LogTemplate{
+			Template:   truncateLogLine(strings.Join(cluster.tokens, " ")),
+			Count:      cluster.count,
+			First:      cluster.first,
+			Last:       cluster.last,
+			Example:    truncateLogLine(cluster.example),
+			Containers: cluster.containers,
+		})
+	}
+	return templates, len(t.all)
+}
+
+// maskLogVariables replaces the variable parts of a log line by placeholders.
+func maskLogVariables(line string) string {
+	for _, variable := range logVariables {
+		line = variable.pattern.ReplaceAllString(line, variable.replacement)
+	}
+	return line
+}
+
+// logTokens splits a log line into tokens at whitespace and after commas,
+// so that the fields of JSON lines and comma-separated lists become tokens.
+func logTokens(line string) []string {
+	var tokens []string
+	for _, field := range strings.Fields(line) {
+		for field != "" {
+			i := strings.IndexByte(field, ',')
+			if i < 0 || i == len(field)-1 {
+				tokens = append(tokens, field)
+				break
+			}
+			tokens = append(tokens, field[:i+1])
+			field = field[i+1:]
+		}
+	}
+	return tokens
+}
+
+// truncateLogLine truncates long templates and examples.
+func truncateLogLine(line string) string {
+	if len(line) <= maxLogExampleLength {
+		return line
+	}
+	return strings.ToValidUTF8(line[:maxLogExampleLength], "") + "..."
+}
+
+// containsString reports whether values contains value.
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
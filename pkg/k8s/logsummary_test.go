@@ -0,0 +1,244 @@
+package k8s
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMaskLogVariables(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{name: "RFC 3339 timestamp", line: "2024-05-01T12:30:45.123Z started", want: "<TS> started"},
+		{name: "timestamp with offset", line: "at 2024-05-01 12:30:45,5+02:00 done", want: "at <TS> done"},
+		{name: "time of day", line: "12:30:45 tick", want: "<TS> tick"},
+		{name: "UUID", line: "request 3F2504E0-4F89-11D3-9A0C-0305E82C3301 done", want: "request <UUID> done"},
+		{name: "IPv4 with port", line: "connect to 10.0.0.12:5432 failed", want: "connect to <IP> failed"},
+		{name: "IPv6", line: "peer fe80:0:0:0:200:f8ff:fe21:67cf left", want: "peer <IP> left"},
+		{name: "hex with prefix", line: "addr 0xDEADbeef", want: "addr <HEX>"},
+		{name: "hex digest", line: "commit 3a5f9c1", want: "commit <HEX>"},
+		{name: "hex-only word without digits", line: "cafe added", want: "cafe added"},
+		{name: "integer and decimal", line: "took 12.5ms for 3 items", want: "took <NUM>ms for <NUM> items"},
+		{name: "digits inside a word", line: "pod web-7 ready", want: "pod web-<NUM> ready"},
+		{name: "no variables", line: "shutting down", want: "shutting down"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := maskLogVariables(tt.line); got != tt.want {
+				t.Errorf("maskLogVariables(%q) = %q; want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogTokens(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{line: "", want: nil},
+		{line: "  \t ", want: nil},
+		{line: "GET /healthz 200", want: []string{"GET", "/healthz", "200"}},
+		{line: "a  b\tc", want: []string{"a", "b", "c"}},
+		{line: "user=<NUM>,role=admin,team=ops done", want: []string{"user=<NUM>,", "role=admin,", "team=ops", "done"}},
+		{line: "list a, b, c", want: []string{"list", "a,", "b,", "c"}},
+		{line: "trailing,", want: []string{"trailing,"}},
+		{line: ",,x", want: []string{",", ",", "x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			if got := logTokens(tt.line); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("logTokens(%q) = %q; want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogTemplates(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	type line struct {
+		text      string
+		container string
+	}
+	tests := []struct {
+		name  string
+		lines []line
+		max   int
+		want  []LogTemplate
+		total int
+	}{
+		{
+			name: "variables are masked before clustering",
+			lines: []line{
+				{"GET /users/1 took 5ms", "app"},
+				{"GET /users/42 took 17ms", "app"},
+			},
+			max: 10,
+			want: []LogTemplate{
+				{Template: "GET /users/<NUM> took <NUM>ms", Count: 2, Example: "GET /users/1 took 5ms", Containers: []string{"app"}},
+			},
+			total: 1,
+		},
+		{
+			name: "differing tokens become wildcards",
+			lines: []line{
+				{"user alice logged in", "app"},
+				{"user bob logged in", "app"},
+				{"user carol logged out", "app"},
+			},
+			max: 10,
+			want: []LogTemplate{
+				{Template: "user <*> logged <*>", Count: 3, Example: "user alice logged in", Containers: []string{"app"}},
+			},
+			total: 1,
+		},
+		{
+			name: "dissimilar lines of the same length stay apart",
+			lines: []line{
+				{"cache hit for key", "app"},
+				{"cache evicted old entries now", "app"},
+				{"cache miss on shard", "app"},
+			},
+			max: 10,
+			want: []LogTemplate{
+				{Template: "cache hit for key", Count: 1, Example: "cache hit for key", Containers: []string{"app"}},
+				{Template: "cache evicted old entries now", Count: 1, Example: "cache evicted old entries now", Containers: []string{"app"}},
+				{Template: "cache miss on shard", Count: 1, Example: "cache miss on shard", Containers: []string{"app"}},
+			},
+			total: 3,
+		},
+		{
+			name: "ordered by count, ties in creation order, containers collected",
+			lines: []line{
+				{"worker started", "app"},
+				{"queue drained completely", "app"},
+				{"connection reset by peer", "sidecar"},
+				{"connection reset by peer", "app"},
+				{"worker started", "app"},
+			},
+			max: 10,
+			want: []LogTemplate{
+				{Template: "worker started", Count: 2, Example: "worker started", Containers: []string{"app"}},
+				{Template: "connection reset by peer", Count: 2, Example: "connection reset by peer", Containers: []string{"sidecar", "app"}},
+				{Template: "queue drained completely", Count: 1, Example: "queue drained completely", Containers: []string{"app"}},
+			},
+			total: 3,
+		},
+		{
+			name: "limited to the most frequent",
+			lines: []line{
+				{"alpha", "app"},
+				{"beta", "app"},
+				{"beta", "app"},
+				{"gamma", "app"},
+			},
+			max: 2,
+			want: []LogTemplate{
+				{Template: "beta", Count: 2, Example: "beta", Containers: []string{"app"}},
+				{Template: "alpha", Count: 1, Example: "alpha", Containers: []string{"app"}},
+			},
+			total: 3,
+		},
+		{
+			name:  "blank lines are ignored",
+			lines: []line{{"", "app"}, {"   ", "app"}},
+			max:   10,
+			want:  []LogTemplate{},
+			total: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			templates := newLogTemplates()
+			for i, l := range tt.lines {
+				templates.add(l.text, l.container, start.Add(time.Duration(i)*time.Second))
+			}
+			got, total := templates.top(tt.max)
+			for i := range got {
+				// Times are checked separately
+				got[i].First, got[i].Last = time.Time{}, time.Time{}
+			}
+			if total != tt.total || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("top(%d) = %+v, %d; want %+v, %d", tt.max, got, total, tt.want, tt.total)
+			}
+		})
+	}
+}
+
+func TestLogTemplatesTimes(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	templates := newLogTemplates()
+	// Lines of the second container are added after those of the first, but logged earlier
+	templates.add("ready", "app", start.Add(time.Minute))
+	templates.add("ready", "app", start.Add(2*time.Minute))
+	templates.add("ready", "sidecar", start)
+
+	got, _ := templates.top(1)
+	if !got[0].First.Equal(start) || !got[0].Last.Equal(start.Add(2*time.Minute)) {
+		t.Errorf("First, Last = %s, %s; want %s, %s", got[0].First, got[0].Last, start, start.Add(2*time.Minute))
+	}
+}
+
+func TestLogTemplatesGroupCap(t *testing.T) {
+	templates := newLogTemplates()
+	// Lines sharing only their first token are too dissimilar to be merged
+	for i := 0; i < maxLogTemplatesPerGroup+20; i++ {
+		templates.add(fmt.Sprintf("event %s %s", wordFor(i), wordFor(i+1000)), "app", time.Time{})
+	}
+	// Another group is not affected by the cap
+	templates.add("other event here", "app", time.Time{})
+
+	got, total := templates.top(1000)
+	if total != maxLogTemplatesPerGroup+1 {
+		t.Fatalf("%d templates; want %d", total, maxLogTemplatesPerGroup+1)
+	}
+	lines := 0
+	for _, template := range got {
+		lines += template.Count
+	}
+	if lines != maxLogTemplatesPerGroup+21 {
+		t.Errorf("templates count %d lines; want %d", lines, maxLogTemplatesPerGroup+21)
+	}
+	if !strings.Contains(got[0].Template, logTemplateWildcard) || got[0].Count < 2 {
+		t.Errorf("lines over the cap were not merged into a template: %+v", got[0])
+	}
+}
+
+// wordFor returns a distinct word without digits for n, so that it is not masked.
+func wordFor(n int) string {
+	var word []byte
+	for {
+		word = append(word, byte('g'+n%20))
+		n /= 20
+		if n == 0 {
+			return string(word)
+		}
+	}
+}
+
+func TestLogTemplatesTieOrder(t *testing.T) {
+	templates := newLogTemplates()
+	var twice, once []string
+	for i := 0; i < 50; i++ {
+		templates.add(wordFor(i), "app", time.Time{})
+		if i%2 == 0 {
+			templates.add(wordFor(i), "app", time.Time{})
+			twice = append(twice, wordFor(i))
+		} else {
+			once = append(once, wordFor(i))
+		}
+	}
+	want := append(twice, once...)
+
+	got, _ := templates.top(len(want))
+	for i, template := range got {
+		if template.Template != want[i] {
+			t.Fatalf("template %d = %q; want %q: templates with the same count must keep the order of their first line", i, template.Template, want[i])
+		}
+	}
+}
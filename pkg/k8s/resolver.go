@@ -0,0 +1,240 @@
+package k8s
+
+import (
+	stderrors "errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+)
+
+// ResourceInfo describes an API resource resolved from a resource type argument.
+type ResourceInfo struct {
+	GVR        schema.GroupVersionResource
+	Kind       string
+	Namespaced bool
+}
+
+// String returns the fully qualified form of the resource, such as
+// "deployments.v1.apps" or "pods.v1" for the core group.
+func (r ResourceInfo) String() string {
+	if r.GVR.Group == "" {
+		return r.GVR.Resource + "." + r.GVR.Version
+	}
+	return r.GVR.Resource + "." + r.GVR.Version + "." + r.GVR.Group
+}
+
+// AmbiguousResourceError is returned when a resource type matches resources
+// in more than one API group.
+type AmbiguousResourceError struct {
+	Input      string
+	Candidates []ResourceInfo
+}
+
+func (e *AmbiguousResourceError) Error() string {
+	candidates := make([]string, 0, len(e.Candidates))
+	for _, candidate := range e.Candidates {
+		candidates = append(candidates, fmt.Sprintf("%s (kind %s)", candidate.String(), candidate.Kind))
+	}
+	return fmt.Sprintf("resource type %q is ambiguous; qualify it with the API group (e.g. %s) to pick one of: %s",
+		e.Input, e.Candidates[0].String(), strings.Join(candidates, ", "))
+}
+
+// versionPattern matches API versions such as v1, v2beta1 or v1alpha3.
+var versionPattern = regexp.MustCompile(`^v[0-9]+((alpha|beta)[0-9]+)?$`)
+
+// ResolveResource resolves a resource type to its API resource, the way
+// kubectl resolves resource arguments. resourceType may be:
+//   - a kind, plural or singular resource name or short name, in any case
+//     (Deployment, deployments, deployment, deploy)
+//   - one of those qualified with a group, or a version and group
+//     (deployments.apps, Deployment.v1.apps, ingress.networking.k8s.io);
+//     the core group is written as "core" or left out (pods.v1, Event.core)
+//
+// When apiVersion is set, as for objects decoded from a manifest, only that
+// group version is searched; otherwise the preferred version of every group
+// is. Kinds and resource names take precedence over short names. Like
+// kubectl, a type without a group that matches a core resource resolves to
+// it, so Event means events.v1 rather than events.v1.events.k8s.io. A type
+// still matching resources in several groups returns an
+// *AmbiguousResourceError listing them. A type that is not found triggers a
+// discovery refresh, so resources of newly installed CRDs resolve at once.
+func (c *Client) ResolveResource(resourceType, apiVersion string) (*ResourceInfo, error) {
+	c.expireDiscovery()
+
+	cacheKey := resourceType + "@" + apiVersion
+	c.cacheLock.RLock()
+	if info, exists := c.resourceCache[cacheKey]; exists {
+		c.cacheLock.RUnlock()
+		return info, nil
+	}
+	c.cacheLock.RUnlock()
+
+	name, gv, groupSet, err := parseResourceType(resourceType, apiVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := c.findResources(name, gv, groupSet)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 && c.refreshDiscoveryOnMiss() {
+		if candidates, err = c.findResources(name, gv, groupSet); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(candidates) > 1 && !groupSet {
+		candidates = preferCoreGroup(candidates)
+	}
+
+	switch len(candidates) {
+	case 0:
+		if apiVersion != "" {
+			return nil, fmt.Errorf("resource type %s not found in %s", resourceType, apiVersion)
+		}
+		return nil, fmt.Errorf("resource type %s not found", resourceType)
+	case 1:
+	default:
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i].String() < candidates[j].String() })
+		return nil, &AmbiguousResourceError{Input: resourceType, Candidates: candidates}
+	}
+
+	info := &candidates[0]
+	c.cacheLock.Lock()
+	c.resourceCache[cacheKey] = info
+	c.cacheLock.Unlock()
+	return info, nil
+}
+
+// findResources returns the discovered resources that name, qualified with gv,
+// refers to: those it names by kind or resource name, or else those it names
+// by short name.
+func (c *Client) findResources(name string, gv schema.GroupVersion, groupSet bool) ([]ResourceInfo, error) {
+	var resourceLists []*metav1.APIResourceList
+	if gv.Version != "" {
+		// A specific version may not be the preferred one, so ask for it directly
+		resourceList, err := c.discoveryClient.ServerResourcesForGroupVersion(gv.String())
+		if err != nil && !errors.IsNotFound(err) && !stderrors.Is(err, memory.ErrCacheNotFound) {
+			return nil, fmt.Errorf("failed to retrieve API resources for %s: %w", gv.String(), err)
+		}
+		if resourceList != nil {
+			resourceLists = append(resourceLists, resourceList)
+		}
+	} else {
+		var err error
+		resourceLists, err = c.discoveryClient.ServerPreferredResources()
+		if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+			return nil, fmt.Errorf("failed to retrieve API resources: %w", err)
+		}
+	}
+
+	var byName, byShortName []ResourceInfo
+	for _, resourceList := range resourceLists {
+		listGV, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			continue
+		}
+		if groupSet && listGV.Group != gv.Group {
+			continue
+		}
+		for _, resource := range resourceList.APIResources {
+			// Subresources such as deployments/scale share the kind of their parent
+			if strings.Contains(resource.Name, "/") {
+				continue
+			}
+			info := ResourceInfo{
+				GVR:        listGV.WithResource(resource.Name),
+				Kind:       resource.Kind,
+				Namespaced: resource.Namespaced,
+			}
+			switch {
+			case matchesResourceName(resource, name):
+				byName = append(byName, info)
+			case matchesShortName(resource, name):
+				byShortName = append(byShortName, info)
+			}
+		}
+	}
+
+	if len(byName) > 0 {
+		return byName, nil
+	}
+	return byShortName, nil
+}
+
+// preferCoreGroup returns the candidate of the core group, if there is one,
+// or else all candidates.
+func preferCoreGroup(candidates []ResourceInfo) []ResourceInfo {
+	for _, candidate := range candidates {
+		if candidate.GVR.Group == "" {
+			return []ResourceInfo{candidate}
+		}
+	}
+	return candidates
+}
+
+// parseResourceType splits a resource type into the resource name and the
+// group version it is qualified with. groupSet reports whether a group was
+// given, since the core group is the empty string.
+func parseResourceType(resourceType, apiVersion string) (name string, gv schema.GroupVersion, groupSet bool, err error) {
+	name = strings.TrimSpace(resourceType)
+	if name == "" {
+		return "", gv, false, fmt.Errorf("resource type is required")
+	}
+
+	if apiVersion != "" {
+		gv, err = schema.ParseGroupVersion(apiVersion)
+		if err != nil {
+			return "", gv, false, fmt.Errorf("invalid apiVersion %q: %w", apiVersion, err)
+		}
+		return name, gv, true, nil
+	}
+
+	name, qualifier, qualified := strings.Cut(name, ".")
+	if !qualified {
+		return name, gv, false, nil
+	}
+
+	// The qualifier is "group", "version" or "version.group"
+	version, group, _ := strings.Cut(qualifier, ".")
+	if versionPattern.MatchString(version) {
+		gv.Version = version
+	} else {
+		group = qualifier
+	}
+	if group == "core" {
+		group = ""
+	}
+	gv.Group = group
+	return name, gv, true, nil
+}
+
+// matchesResourceName reports whether name is the kind, plural or singular
+// name of a resource, ignoring case.
+func matchesResourceName(resource metav1.APIResource, name string) bool {
+	singular := resource.SingularName
+	if singular == "" {
+		singular = resource.Kind
+	}
+	return strings.EqualFold(resource.Kind, name) ||
+		strings.EqualFold(resource.Name, name) ||
+		strings.EqualFold(singular, name)
+}
+
+// matchesShortName reports whether name is one of the short names of a resource.
+func matchesShortName(resource metav1.APIResource, name string) bool {
+	for _, shortName := range resource.ShortNames {
+		if strings.EqualFold(shortName, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package k8s
+
+import (
+	"errors"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery/cached/memory"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// newResolverClient returns a client whose discovery serves resources.
+func newResolverClient(resources []*metav1.APIResourceList) *Client {
+	fake := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: resources}}
+	return &Client{
+		discoveryClient: memory.NewMemCacheClient(fake),
+		resourceCache:   make(map[string]*ResourceInfo),
+	}
+}
+
+func TestResolveResource(t *testing.T) {
+	client := newResolverClient([]*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "pods", SingularName: "pod", Kind: "Pod", Namespaced: true, ShortNames: []string{"po"}},
+				{Name: "events", SingularName: "event", Kind: "Event", Namespaced: true, ShortNames: []string{"ev"}},
+				{Name: "namespaces", SingularName: "namespace", Kind: "Namespace", ShortNames: []string{"ns"}},
+			},
+		},
+		{
+			GroupVersion: "events.k8s.io/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "events", SingularName: "event", Kind: "Event", Namespaced: true, ShortNames: []string{"ev"}},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", SingularName: "deployment", Kind: "Deployment", Namespaced: true, ShortNames: []string{"deploy"}},
+				{Name: "deployments/scale", Kind: "Scale", Namespaced: true},
+			},
+		},
+		{
+			GroupVersion: "example.com/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "widgets", SingularName: "widget", Kind: "Widget", Namespaced: true},
+			},
+		},
+		{
+			GroupVersion: "other.example.com/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "widgets", SingularName: "widget", Kind: "Widget"},
+			},
+		},
+	})
+
+	tests := []struct {
+		name         string
+		resourceType string
+		apiVersion   string
+		want         string
+		namespaced   bool
+		ambiguous    bool
+		notFound     bool
+	}{
+		{name: "kind", resourceType: "Pod", want: "pods.v1", namespaced: true},
+		{name: "plural in lower case", resourceType: "deployments", want: "deployments.v1.apps", namespaced: true},
+		{name: "short name", resourceType: "deploy", want: "deployments.v1.apps", namespaced: true},
+		{name: "cluster-scoped", resourceType: "ns", want: "namespaces.v1"},
+		{name: "core group preferred over events.k8s.io", resourceType: "Event", want: "events.v1", namespaced: true},
+		{name: "core group preferred for short names", resourceType: "ev", want: "events.v1", namespaced: true},
+		{name: "qualified with group", resourceType: "Event.events.k8s.io", want: "events.v1.events.k8s.io", namespaced: true},
+		{name: "qualified with core group", resourceType: "events.core", want: "events.v1", namespaced: true},
+		{name: "apiVersion", resourceType: "Event", apiVersion: "events.k8s.io/v1", want: "events.v1.events.k8s.io", namespaced: true},
+		{name: "ambiguous outside the core group", resourceType: "Widget", ambiguous: true},
+		{name: "qualified custom resource", resourceType: "widgets.other.example.com", want: "widgets.v1.other.example.com"},
+		{name: "unknown", resourceType: "Gadget", notFound: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := client.ResolveResource(tt.resourceType, tt.apiVersion)
+			var ambiguous *AmbiguousResourceError
+			switch {
+			case tt.ambiguous:
+				if !errors.As(err, &ambiguous) || len(ambiguous.Candidates) != 2 {
+					t.Fatalf("ResolveResource(%q) = %v, %v; want an ambiguity error with 2 candidates", tt.resourceType, info, err)
+				}
+			case tt.notFound:
+				if err == nil {
+					t.Fatalf("ResolveResource(%q) = %v; want an error", tt.resourceType, info)
+				}
+			case err != nil:
+				t.Fatalf("ResolveResource(%q) failed: %v", tt.resourceType, err)
+			case info.String() != tt.want || info.Namespaced != tt.namespaced:
+				t.Errorf("ResolveResource(%q) = %s (namespaced %v); want %s (namespaced %v)", tt.resourceType, info, info.Namespaced, tt.want, tt.namespaced)
+			}
+		})
+	}
+}
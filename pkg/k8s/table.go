@@ -0,0 +1,152 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+// tableAcceptHeader asks the API server for the meta.k8s.io/v1 Table
+// representation that kubectl get prints, falling back to plain JSON for
+// servers that cannot produce it.
+const tableAcceptHeader = "application/json;as=Table;v=v1;g=meta.k8s.io,application/json"
+
+// Table is a compact tabular view of resources as printed by kubectl get,
+// including the additional printer columns of custom resources.
+type Table struct {
+	Columns []string        `json:"columns"`
+	Rows    [][]interface{} `json:"rows"`
+	// Continue and RemainingItemCount have the same meaning as in ResourceList.
+	Continue           string `json:"continue,omitempty"`
+	RemainingItemCount *int64 `json:"remainingItemCount,omitempty"`
+}
+
+// ListResourcesTable lists instances of a resource type like ListResources,
+// but returns the API server's Table representation: the columns kubectl get
+// shows and one row of cells per resource. When listing a namespaced type
+// across all namespaces, a leading Namespace column is added.
+// options.Columns is not supported with tables.
+func (c *Client) ListResourcesTable(ctx context.Context, kind, namespace string, options ListOptions) (*Table, error) {
+	if len(options.Columns) > 0 {
+		return nil, fmt.Errorf("fields cannot be combined with table output")
+	}
+
+	info, err := c.ResolveResource(kind, "")
+	if err != nil {
+		return nil, err
+	}
+
+	request := c.tableRequest(info, namespace, "").
+		VersionedParams(&metav1.ListOptions{
+			LabelSelector: options.LabelSelector,
+			FieldSelector: options.FieldSelector,
+			Limit:         options.Limit,
+			Continue:      options.Continue,
+		}, scheme.ParameterCodec)
+	table, err := fetchTable(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resources: %w", err)
+	}
+
+	return compactTable(table, info.Namespaced && namespace == "")
+}
+
+// GetResourceTable retrieves a single resource as a one-row Table.
+func (c *Client) GetResourceTable(ctx context.Context, kind, name, namespace string) (*Table, error) {
+	info, err := c.ResolveResource(kind, "")
+	if err != nil {
+		return nil, err
+	}
+
+	table, err := fetchTable(ctx, c.tableRequest(info, namespace, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve resource: %w", err)
+	}
+
+	return compactTable(table, false)
+}
+
+// tableRequest builds a GET request for a resource, or a list of resources
+// when name is empty, in the Table representation.
+func (c *Client) tableRequest(info *ResourceInfo, namespace, name string) *rest.Request {
+	segments := []string{"/api", info.GVR.Version}
+	if info.GVR.Group != "" {
+		segments = []string{"/apis", info.GVR.Group, info.GVR.Version}
+	}
+	if info.Namespaced && namespace != "" {
+		segments = append(segments, "namespaces", namespace)
+	}
+	segments = append(segments, info.GVR.Resource)
+	if name != "" {
+		segments = append(segments, name)
+	}
+
+	return c.clientset.CoreV1().RESTClient().Get().
+		AbsPath(path.Join(segments...)).
+		SetHeader("Accept", tableAcceptHeader).
+		Param("includeObject", string(metav1.IncludeMetadata))
+}
+
+// fetchTable runs a table request and decodes the response.
+func fetchTable(ctx context.Context, request *rest.Request) (*metav1.Table, error) {
+	raw, err := request.DoRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	table := &metav1.Table{}
+	if err := json.Unmarshal(raw, table); err != nil {
+		return nil, fmt.Errorf("failed to decode table: %w", err)
+	}
+	if table.Kind != "Table" {
+		return nil, fmt.Errorf("the API server does not support table output for this resource (got %s)", table.Kind)
+	}
+	return table, nil
+}
+
+// compactTable keeps the columns kubectl get shows without -o wide and the
+// cells of every row, prefixed with the row's namespace if withNamespace is set.
+func compactTable(table *metav1.Table, withNamespace bool) (*Table, error) {
+	var columns []int
+	result := &Table{
+		Rows:               make([][]interface{}, 0, len(table.Rows)),
+		Continue:           table.Continue,
+		RemainingItemCount: table.RemainingItemCount,
+	}
+	if withNamespace {
+		result.Columns = append(result.Columns, "Namespace")
+	}
+	for i, column := range table.ColumnDefinitions {
+		if column.Priority == 0 {
+			columns = append(columns, i)
+			result.Columns = append(result.Columns, column.Name)
+		}
+	}
+
+	for _, row := range table.Rows {
+		cells := make([]interface{}, 0, len(result.Columns))
+		if withNamespace {
+			metadata := metav1.PartialObjectMetadata{}
+			if len(row.Object.Raw) > 0 {
+				if err := json.Unmarshal(row.Object.Raw, &metadata); err != nil {
+					return nil, fmt.Errorf("failed to decode table row metadata: %w", err)
+				}
+			}
+			cells = append(cells, metadata.Namespace)
+		}
+		for _, i := range columns {
+			if i < len(row.Cells) {
+				cells = append(cells, row.Cells[i])
+			} else {
+				cells = append(cells, nil)
+			}
+		}
+		result.Rows = append(result.Rows, cells)
+	}
+	return result, nil
+}
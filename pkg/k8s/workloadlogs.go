@@ -0,0 +1,245 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DefaultWorkloadLogTailLines is the number of lines returned from the end of
+// each container's logs by GetWorkloadLogs unless another number is requested.
+// It is lower than DefaultLogTailLines since logs of many pods are combined.
+const DefaultWorkloadLogTailLines = 20
+
+// MaxWorkloadLogPods is the maximum number of pods GetWorkloadLogs fetches
+// logs from. Further pods are counted but skipped.
+const MaxWorkloadLogPods = 50
+
+// workloadLogConcurrency bounds the number of log requests GetWorkloadLogs
+// runs at the same time, like kubectl's --max-log-requests.
+const workloadLogConcurrency = 5
+
+// LogLine is a line of a container's logs.
+type LogLine struct {
+	Pod       string
+	Container string
+	// Time is the time the kubelet recorded the line at.
+	Time time.Time
+	Text string
+}
+
+// WorkloadLogs are the interleaved logs of the pods matching a selector.
+type WorkloadLogs struct {
+	Selector string
+	Pods     []string
+	// OmittedPods is the number of matching pods beyond MaxWorkloadLogPods
+	// whose logs were not fetched.
+	OmittedPods int
+	// Lines holds the lines of all containers, ordered by time.
+	Lines []LogLine
+	// Errors lists the containers whose logs could not be fetched, as
+	// "pod/container: error".
+	Errors []string
+	// Filtered reports whether the lines were selected by a LogFilter.
+	// TotalLines and MatchedLines then count the lines read and matched.
+	Filtered     bool
+	TotalLines   int
+	MatchedLines int
+	// Timestamps reports whether String prefixes lines with their timestamps.
+	Timestamps bool
+}
+
+// String renders the logs like kubectl logs --prefix: every line is prefixed
+// with "[pod/container]", followed by the errors of containers whose logs
+// could not be fetched.
+func (l *WorkloadLogs) String() string {
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("--- Logs of %d pods selected by %s", len(l.Pods), l.Selector))
+	if l.OmittedPods > 0 {
+		out.WriteString(fmt.Sprintf(" (%d more pods omitted)", l.OmittedPods))
+	}
+	if l.Filtered {
+		out.WriteString(fmt.Sprintf(", %d of %d lines matched", l.MatchedLines, l.TotalLines))
+	}
+	out.WriteString(" ---\n")
+
+	for _, line := range l.Lines {
+		out.WriteString(fmt.Sprintf("[%s/%s] ", line.Pod, line.Container))
+		if l.Timestamps && !line.Time.IsZero() {
+			out.WriteString(line.Time.Format(time.RFC3339Nano) + " ")
+		}
+		out.WriteString(line.Text + "\n")
+	}
+
+	if len(l.Errors) > 0 {
+		out.WriteString("--- Errors ---\n")
+		for _, err := range l.Errors {
+			out.WriteString(err + "\n")
+		}
+	}
+	return out.String()
+}
+
+// GetWorkloadLogs retrieves the logs of all pods of a workload, such as a
+// Deployment, StatefulSet, DaemonSet, ReplicaSet or Job, or of the pods
+// matching labelSelector, similar to kubectl logs -l or stern.
+// Exactly one of kind and name, or labelSelector, must be given.
+// The logs are fetched concurrently, at most workloadLogConcurrency requests
+// at a time, from at most MaxWorkloadLogPods pods, and their lines are
+// interleaved by timestamp. options select the containers and lines as for
+// GetPodsLogs, except that TailLines defaults to DefaultWorkloadLogTailLines.
+// options.Filter is applied to every container's logs while they are streamed.
+func (c *Client) GetWorkloadLogs(ctx context.Context, namespace, kind, name, labelSelector string, options LogOptions) (*WorkloadLogs, error) {
+	// Timestamps are needed to interleave the lines; they are only shown if requested
+	showTimestamps := options.Timestamps
+	options.Timestamps = true
+	if _, err := options.podLogOptions("", DefaultWorkloadLogTailLines); err != nil {
+		return nil, err
+	}
+
+	var selector labels.Selector
+	var err error
+	switch {
+	case labelSelector != "" && (kind != "" || name != ""):
+		return nil, fmt.Errorf("either kind and name or labelSelector must be given, not both")
+	case labelSelector != "":
+		if selector, err = labels.Parse(labelSelector); err != nil {
+			return nil, fmt.Errorf("invalid label selector: %w", err)
+		}
+	case kind != "" && name != "":
+		if selector, err = c.workloadSelector(ctx, namespace, kind, name); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("either kind and name or labelSelector must be given")
+	}
+
+	podList, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	pods := podList.Items
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+
+	result := &WorkloadLogs{Selector: selector.String(), Timestamps: showTimestamps, Filtered: options.Filter != nil}
+	if len(pods) > MaxWorkloadLogPods {
+		result.OmittedPods = len(pods) - MaxWorkloadLogPods
+		pods = pods[:MaxWorkloadLogPods]
+	}
+
+	type logRequest struct {
+		pod       string
+		container string
+	}
+	var requests []logRequest
+	for i := range pods {
+		result.Pods = append(result.Pods, pods[i].Name)
+		for _, container := range options.logContainers(&pods[i]) {
+			requests = append(requests, logRequest{pod: pods[i].Name, container: container})
+		}
+	}
+
+	// Every request writes its own slot, so no locking is needed
+	logs := make([]*logSelection, len(requests))
+	errs := make([]error, len(requests))
+	semaphore := make(chan struct{}, workloadLogConcurrency)
+	var wg sync.WaitGroup
+	for i, request := range requests {
+		wg.Add(1)
+		go func(i int, request logRequest) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			logs[i], errs[i] = c.containerLogs(ctx, namespace, request.pod, request.container, options, DefaultWorkloadLogTailLines)
+		}(i, request)
+	}
+	wg.Wait()
+
+	for i, request := range requests {
+		if errs[i] != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s/%s: %v", request.pod, request.container, errs[i]))
+			continue
+		}
+		result.Lines = append(result.Lines, parseLogLines(request.pod, request.container, logs[i].lines)...)
+		result.TotalLines += logs[i].total
+		result.MatchedLines += logs[i].matched
+	}
+	sort.SliceStable(result.Lines, func(i, j int) bool {
+		return result.Lines[i].Time.Before(result.Lines[j].Time)
+	})
+	return result, nil
+}
+
+// workloadSelector returns the pod selector of a workload. Most workloads
+// have a label selector in spec.selector; Services and ReplicationControllers
+// have a plain map of labels there.
+func (c *Client) workloadSelector(ctx context.Context, namespace, kind, name string) (labels.Selector, error) {
+	resource, err := c.resourceInterface(kind, namespace)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := resource.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve resource: %w", err)
+	}
+
+	content, found, err := unstructured.NestedMap(obj.Object, "spec", "selector")
+	if err != nil || !found || len(content) == 0 {
+		return nil, fmt.Errorf("%s %s has no pod selector", obj.GetKind(), name)
+	}
+
+	var selector labels.Selector
+	_, hasLabels := content["matchLabels"]
+	_, hasExpressions := content["matchExpressions"]
+	if hasLabels || hasExpressions {
+		labelSelector := &metav1.LabelSelector{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(content, labelSelector); err != nil {
+			return nil, fmt.Errorf("invalid pod selector of %s %s: %w", obj.GetKind(), name, err)
+		}
+		if selector, err = metav1.LabelSelectorAsSelector(labelSelector); err != nil {
+			return nil, fmt.Errorf("invalid pod selector of %s %s: %w", obj.GetKind(), name, err)
+		}
+	} else {
+		matchLabels, _, err := unstructured.NestedStringMap(obj.Object, "spec", "selector")
+		if err != nil {
+			return nil, fmt.Errorf("invalid pod selector of %s %s: %w", obj.GetKind(), name, err)
+		}
+		selector = labels.SelectorFromSet(matchLabels)
+	}
+
+	// An empty selector would match every pod in the namespace
+	if selector.Empty() {
+		return nil, fmt.Errorf("%s %s has no pod selector", obj.GetKind(), name)
+	}
+	return selector, nil
+}
+
+// parseLogLines parses the lines of a container's logs, requested with
+// timestamps. Lines without a valid timestamp take the time of the line
+// before them, so they stay in place when lines are interleaved.
+func parseLogLines(pod, container string, selected []selectedLine) []LogLine {
+	lines := make([]LogLine, 0, len(selected))
+	var last time.Time
+	for _, selectedLine := range selected {
+		text := selectedLine.text
+		line := LogLine{Pod: pod, Container: container, Time: last, Text: text}
+		if timestamp, rest, ok := strings.Cut(text, " "); ok {
+			if parsed, err := time.Parse(time.RFC3339Nano, timestamp); err == nil {
+				line.Time = parsed
+				line.Text = rest
+				last = parsed
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,140 @@
+// Package kubeconfig provides the single place where the server turns its
+// kubeconfig, context and in-cluster settings into Kubernetes REST configs,
+// so the Kubernetes and Helm clients can never disagree on the target cluster.
+package kubeconfig
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// InClusterContext is the name of the synthetic context exposed when the
+// server authenticates with the pod's service account.
+const InClusterContext = "in-cluster"
+
+// Options controls how the Kubernetes configuration is loaded.
+type Options struct {
+	// Kubeconfig is a kubeconfig path or a list of paths separated by the OS
+	// path list separator, merged the same way kubectl merges KUBECONFIG.
+	// When empty, KUBECONFIG and then ~/.kube/config are used.
+	Kubeconfig string
+	// Context overrides the kubeconfig's current context.
+	Context string
+	// InCluster forces the use of the pod's service account.
+	InCluster bool
+}
+
+// Loader loads raw kubeconfigs and REST configs according to Options.
+type Loader struct {
+	options      Options
+	loadingRules *clientcmd.ClientConfigLoadingRules
+}
+
+// NewLoader creates a new Loader.
+// It returns an error if the options are contradictory.
+func NewLoader(options Options) (*Loader, error) {
+	if options.InCluster && (options.Kubeconfig != "" || options.Context != "") {
+		return nil, fmt.Errorf("in-cluster mode cannot be combined with a kubeconfig or context")
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if paths := filepath.SplitList(options.Kubeconfig); len(paths) == 1 {
+		loadingRules.ExplicitPath = paths[0]
+	} else if len(paths) > 1 {
+		loadingRules.Precedence = paths
+	}
+
+	return &Loader{
+		options:      options,
+		loadingRules: loadingRules,
+	}, nil
+}
+
+// RawConfig returns the merged kubeconfig with the configured context applied
+// as the current context. In in-cluster mode it returns a config with a single
+// synthetic context named InClusterContext.
+func (l *Loader) RawConfig() (*clientcmdapi.Config, error) {
+	rawConfig, _, err := l.load()
+	return rawConfig, err
+}
+
+// RESTConfig returns the REST config for the given context.
+// An empty context name selects the current context.
+func (l *Loader) RESTConfig(contextName string) (*rest.Config, error) {
+	rawConfig, inCluster, err := l.load()
+	if err != nil {
+		return nil, err
+	}
+
+	if contextName == "" {
+		contextName = rawConfig.CurrentContext
+	}
+	if _, ok := rawConfig.Contexts[contextName]; !ok {
+		return nil, fmt.Errorf("context %q not found in kubeconfig", contextName)
+	}
+
+	if inCluster {
+		restConfig, err := rest.InClusterConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create in-cluster configuration: %w", err)
+		}
+		return restConfig, nil
+	}
+
+	restConfig, err := clientcmd.NewNonInteractiveClientConfig(
+		*rawConfig, contextName, &clientcmd.ConfigOverrides{}, l.loadingRules,
+	).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create configuration for context %q: %w", contextName, err)
+	}
+	return restConfig, nil
+}
+
+// load returns the raw config and whether it represents the in-cluster
+// service account rather than a kubeconfig file.
+func (l *Loader) load() (*clientcmdapi.Config, bool, error) {
+	if l.options.InCluster {
+		return inClusterRawConfig(), true, nil
+	}
+
+	rawConfig, err := l.loadingRules.Load()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+
+	// Fall back to the pod's service account when no kubeconfig is available,
+	// matching kubectl's behaviour inside a cluster.
+	if len(rawConfig.Contexts) == 0 && l.options.Kubeconfig == "" && l.options.Context == "" && inClusterPossible() {
+		return inClusterRawConfig(), true, nil
+	}
+
+	if l.options.Context != "" {
+		rawConfig.CurrentContext = l.options.Context
+	}
+	return rawConfig, false, nil
+}
+
+// inClusterPossible reports whether the process runs inside a pod with a
+// mounted service account.
+func inClusterPossible() bool {
+	_, err := rest.InClusterConfig()
+	return err == nil
+}
+
+// inClusterRawConfig builds a kubeconfig with a single synthetic context
+// representing the pod's service account.
+func inClusterRawConfig() *clientcmdapi.Config {
+	rawConfig := clientcmdapi.NewConfig()
+	rawConfig.Clusters[InClusterContext] = clientcmdapi.NewCluster()
+	rawConfig.AuthInfos[InClusterContext] = clientcmdapi.NewAuthInfo()
+	rawConfig.Contexts[InClusterContext] = &clientcmdapi.Context{
+		Cluster:  InClusterContext,
+		AuthInfo: InClusterContext,
+	}
+	rawConfig.CurrentContext = InClusterContext
+	return rawConfig
+}
@@ -0,0 +1,181 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/audit"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/auth"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/cluster"
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// manifestArguments are the tool arguments that carry JSON or YAML manifests.
+var manifestArguments = []string{"manifest", "yamlManifest"}
+
+// Middleware returns a tool handler middleware that evaluates every call
+// against the policy before the handler runs. Denied calls return an error
+// result explaining the denial, so the model can adjust its request.
+func Middleware(policy *Policy, registry *cluster.Registry) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			for _, req := range requestsFor(ctx, request, registry) {
+				if decision := policy.Evaluate(req); !decision.Allowed {
+					audit.SetOutcome(ctx, audit.OutcomeDenied)
+					return mcp.NewToolResultError("Denied by policy: " + decision.Reason), nil
+				}
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// resolveFunc resolves a resource type as k8s.Client.ResolveResource does.
+type resolveFunc func(resourceType, apiVersion string) (*k8s.ResourceInfo, error)
+
+// requestsFor builds the policy requests of a tool call: one per object in
+// its manifest, or a single request built from the arguments.
+func requestsFor(ctx context.Context, request mcp.CallToolRequest, registry *cluster.Registry) []Request {
+	args, _ := request.Params.Arguments.(map[string]interface{})
+
+	base := Request{
+		Tool:      request.Params.Name,
+		Kind:      stringArg(args, "kind"),
+		Namespace: stringArg(args, "namespace"),
+		Context:   stringArg(args, "context"),
+		Arguments: args,
+	}
+	if base.Kind == "" {
+		base.Kind = stringArg(args, "Kind")
+	}
+	if identity, ok := auth.IdentityFromContext(ctx); ok {
+		base.User = identity.Username
+		base.Groups = identity.Groups
+	}
+
+	var resolve resolveFunc
+	if registry != nil {
+		if info, err := registry.Context(base.Context); err == nil {
+			base.Context = info.Name
+		}
+		if client, err := registry.K8s(ctx, base.Context); err == nil {
+			resolve = client.ResolveResource
+		}
+	}
+	return targetRequests(base, args, resolve)
+}
+
+// targetRequests completes base with the kind and namespace of every object
+// the call targets. The namespace is the one the handlers act in: none for
+// cluster-scoped kinds, whose handlers ignore any namespace, so a namespace
+// argument or metadata.namespace cannot make a call on Namespaces, Nodes or
+// ClusterRoleBindings match rules restricted to namespaces.
+func targetRequests(base Request, args map[string]interface{}, resolve resolveFunc) []Request {
+	namespace := base.Namespace
+	kind, clusterScoped := resolveTarget(resolve, base.Kind, "")
+	base.Kind = kind
+	if clusterScoped {
+		base.Namespace = ""
+	}
+
+	var requests []Request
+	for _, key := range manifestArguments {
+		manifest := stringArg(args, key)
+		if manifest == "" {
+			continue
+		}
+		objects, err := decodeManifest(manifest)
+		if err != nil {
+			// The handler rejects invalid manifests; judge the call by its arguments
+			continue
+		}
+		for _, object := range objects {
+			req := base
+			objectClusterScoped := clusterScoped
+			if kind, ok := object["kind"].(string); ok && kind != "" {
+				apiVersion, _ := object["apiVersion"].(string)
+				var scoped bool
+				req.Kind, scoped = resolveTarget(resolve, kind, apiVersion)
+				objectClusterScoped = objectClusterScoped || scoped
+			}
+
+			req.Namespace = namespace
+			switch {
+			case objectClusterScoped:
+				req.Namespace = ""
+			case req.Namespace == "":
+				if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+					req.Namespace, _ = metadata["namespace"].(string)
+				}
+			}
+			requests = append(requests, req)
+		}
+	}
+
+	if len(requests) == 0 {
+		requests = append(requests, base)
+	}
+	return requests
+}
+
+// resolveTarget resolves a resource type given as a plural, short name or
+// group-qualified name, such as "secrets" or "deploy", to its kind, so rules
+// listing kinds cannot be bypassed by spelling the type differently, and
+// reports whether it is cluster-scoped.
+// Types that cannot be resolved are returned unchanged as namespaced; the
+// handler rejects them.
+func resolveTarget(resolve resolveFunc, kind, apiVersion string) (string, bool) {
+	if resolve == nil || kind == "" {
+		return kind, false
+	}
+	info, err := resolve(kind, apiVersion)
+	if err != nil {
+		return kind, false
+	}
+	return info.Kind, !info.Namespaced
+}
+
+// decodeManifest decodes every object of a JSON or multi-document YAML manifest.
+// The items of List objects are returned in place of the list.
+func decodeManifest(manifest string) ([]map[string]interface{}, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader([]byte(manifest)), 4096)
+
+	var objects []map[string]interface{}
+	for {
+		var object map[string]interface{}
+		if err := decoder.Decode(&object); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to decode manifest: %w", err)
+		}
+		if object == nil {
+			continue
+		}
+		if kind, _ := object["kind"].(string); strings.HasSuffix(kind, "List") {
+			if items, ok := object["items"].([]interface{}); ok {
+				for _, item := range items {
+					if itemObject, ok := item.(map[string]interface{}); ok {
+						objects = append(objects, itemObject)
+					}
+				}
+				continue
+			}
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+func stringArg(args map[string]interface{}, key string) string {
+	value, _ := args[key].(string)
+	return value
+}
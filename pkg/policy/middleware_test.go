@@ -0,0 +1,147 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/reza-gholizade/k8s-mcp-server/pkg/k8s"
+)
+
+// fakeResolve resolves the kinds, plurals and short names of a few core resources.
+func fakeResolve(resourceType, apiVersion string) (*k8s.ResourceInfo, error) {
+	resources := []struct {
+		names      []string
+		kind       string
+		namespaced bool
+	}{
+		{names: []string{"pod", "pods", "po"}, kind: "Pod", namespaced: true},
+		{names: []string{"configmap", "configmaps", "cm"}, kind: "ConfigMap", namespaced: true},
+		{names: []string{"namespace", "namespaces", "ns"}, kind: "Namespace"},
+		{names: []string{"node", "nodes", "no"}, kind: "Node"},
+		{names: []string{"clusterrolebinding", "clusterrolebindings"}, kind: "ClusterRoleBinding"},
+	}
+	for _, resource := range resources {
+		for _, name := range resource.names {
+			if strings.EqualFold(name, resourceType) {
+				return &k8s.ResourceInfo{Kind: resource.kind, Namespaced: resource.namespaced}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("resource type %q not found", resourceType)
+}
+
+func TestTargetRequestsClusterScoped(t *testing.T) {
+	policy := &Policy{
+		Default: Allow,
+		Rules: []Rule{
+			{Effect: Allow, Tools: []string{"deleteResource", "applyManifests", "applyResource"}, Namespaces: []string{"dev-*"}},
+			{Effect: Deny, Tools: []string{"deleteResource", "applyManifests", "applyResource"}},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		tool          string
+		args          map[string]interface{}
+		wantKinds     []string
+		wantNamespace []string
+		allowed       bool
+	}{
+		{
+			name:          "namespaced kind in an allowed namespace",
+			tool:          "deleteResource",
+			args:          map[string]interface{}{"kind": "pods", "name": "web", "namespace": "dev-x"},
+			wantKinds:     []string{"Pod"},
+			wantNamespace: []string{"dev-x"},
+			allowed:       true,
+		},
+		{
+			name:          "namespaced kind in another namespace",
+			tool:          "deleteResource",
+			args:          map[string]interface{}{"kind": "Pod", "name": "web", "namespace": "prod"},
+			wantKinds:     []string{"Pod"},
+			wantNamespace: []string{"prod"},
+		},
+		{
+			name:          "Namespace with a namespace argument",
+			tool:          "deleteResource",
+			args:          map[string]interface{}{"kind": "Namespace", "name": "prod", "namespace": "dev-x"},
+			wantKinds:     []string{"Namespace"},
+			wantNamespace: []string{""},
+		},
+		{
+			name:          "Node by short name",
+			tool:          "deleteResource",
+			args:          map[string]interface{}{"kind": "no", "name": "worker-1", "namespace": "dev-x"},
+			wantKinds:     []string{"Node"},
+			wantNamespace: []string{""},
+		},
+		{
+			name:          "ClusterRoleBinding with metadata.namespace",
+			tool:          "applyManifests",
+			args:          map[string]interface{}{"manifest": "apiVersion: rbac.authorization.k8s.io/v1\nkind: ClusterRoleBinding\nmetadata:\n  name: admin\n  namespace: dev-x\n"},
+			wantKinds:     []string{"ClusterRoleBinding"},
+			wantNamespace: []string{""},
+		},
+		{
+			name:          "ClusterRoleBinding with a namespace argument",
+			tool:          "applyResource",
+			args:          map[string]interface{}{"namespace": "dev-x", "manifest": `{"apiVersion":"rbac.authorization.k8s.io/v1","kind":"ClusterRoleBinding","metadata":{"name":"admin"}}`},
+			wantKinds:     []string{"ClusterRoleBinding"},
+			wantNamespace: []string{""},
+		},
+		{
+			name:          "cluster-scoped kind argument with a namespaced manifest",
+			tool:          "applyResource",
+			args:          map[string]interface{}{"kind": "namespaces", "manifest": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n  namespace: dev-x\n"},
+			wantKinds:     []string{"ConfigMap"},
+			wantNamespace: []string{""},
+		},
+		{
+			name:          "namespaced manifest objects in allowed namespaces",
+			tool:          "applyManifests",
+			args:          map[string]interface{}{"manifest": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n  namespace: dev-a\n---\napiVersion: v1\nkind: Pod\nmetadata:\n  name: b\n  namespace: dev-b\n"},
+			wantKinds:     []string{"ConfigMap", "Pod"},
+			wantNamespace: []string{"dev-a", "dev-b"},
+			allowed:       true,
+		},
+		{
+			name:          "Namespace among namespaced manifest objects",
+			tool:          "applyManifests",
+			args:          map[string]interface{}{"manifest": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n  namespace: dev-a\n---\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: prod\n  namespace: dev-a\n"},
+			wantKinds:     []string{"ConfigMap", "Namespace"},
+			wantNamespace: []string{"dev-a", ""},
+		},
+		{
+			name:          "unknown kind keeps the namespace",
+			tool:          "deleteResource",
+			args:          map[string]interface{}{"kind": "Widget", "name": "w", "namespace": "dev-x"},
+			wantKinds:     []string{"Widget"},
+			wantNamespace: []string{"dev-x"},
+			allowed:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := Request{Tool: tt.tool, Kind: stringArg(tt.args, "kind"), Namespace: stringArg(tt.args, "namespace"), Arguments: tt.args}
+			requests := targetRequests(base, tt.args, fakeResolve)
+
+			if len(requests) != len(tt.wantKinds) {
+				t.Fatalf("got %d requests %+v; want %d", len(requests), requests, len(tt.wantKinds))
+			}
+			allowed := true
+			for i, req := range requests {
+				if req.Kind != tt.wantKinds[i] || req.Namespace != tt.wantNamespace[i] {
+					t.Errorf("request %d = %s in %q; want %s in %q", i, req.Kind, req.Namespace, tt.wantKinds[i], tt.wantNamespace[i])
+				}
+				if !policy.Evaluate(req).Allowed {
+					allowed = false
+				}
+			}
+			if allowed != tt.allowed {
+				t.Errorf("allowed = %v; want %v", allowed, tt.allowed)
+			}
+		})
+	}
+}
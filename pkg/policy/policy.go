@@ -0,0 +1,237 @@
+// Package policy decides which tool calls are allowed, based on a YAML policy
+// file matching tool names, resource kinds, namespaces, contexts, callers and
+// arbitrary arguments.
+//
+// Rules are evaluated in order and the first matching rule decides. Calls
+// that match no rule get the policy's default effect.
+//
+// Example:
+//
+//	default: allow
+//	rules:
+//	  - effect: deny
+//	    kinds: [Secret]
+//	    message: Secrets are off limits
+//	  - effect: deny
+//	    tools: [helmUninstall]
+//	    namespaces: [kube-system]
+//	  - effect: allow
+//	    tools: [deleteResource]
+//	    kinds: [Pod]
+//	    namespaces: ["dev-*"]
+//	  - effect: deny
+//	    tools: [deleteResource]
+//	    message: only pods in dev-* namespaces may be deleted
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Effects of a rule.
+const (
+	Allow = "allow"
+	Deny  = "deny"
+)
+
+// Policy is an ordered list of rules.
+type Policy struct {
+	// Default is the effect applied when no rule matches. Defaults to allow.
+	Default string `json:"default,omitempty"`
+	Rules   []Rule `json:"rules"`
+}
+
+// Rule matches tool calls and allows or denies them.
+// Every non-empty field must match for the rule to apply. Tool names,
+// namespaces, contexts, users, groups and argument values are shell-style
+// patterns (see path.Match); kinds are compared case-insensitively with the
+// kind a call's resource type resolves to, so "secrets" matches Secret.
+type Rule struct {
+	Effect     string   `json:"effect"`
+	Tools      []string `json:"tools,omitempty"`
+	Kinds      []string `json:"kinds,omitempty"`
+	Namespaces []string `json:"namespaces,omitempty"`
+	Contexts   []string `json:"contexts,omitempty"`
+	Users      []string `json:"users,omitempty"`
+	Groups     []string `json:"groups,omitempty"`
+	// Arguments maps argument names to patterns their value must match.
+	Arguments map[string][]string `json:"arguments,omitempty"`
+	// Message is returned to the caller when the rule denies a call.
+	Message string `json:"message,omitempty"`
+}
+
+// Request is a single tool call target to evaluate. Tools that act on several
+// objects, such as multi-document manifests, are evaluated once per object.
+type Request struct {
+	Tool      string
+	Kind      string
+	Namespace string
+	Context   string
+	User      string
+	Groups    []string
+	Arguments map[string]interface{}
+}
+
+// Decision is the result of evaluating a Request.
+type Decision struct {
+	Allowed bool
+	// Reason explains a denial.
+	Reason string
+}
+
+// Load reads and validates a policy file.
+func Load(file string) (*Policy, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	var policy Policy
+	if err := yaml.UnmarshalStrict(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file %s: %w", file, err)
+	}
+	if err := policy.validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %w", file, err)
+	}
+	return &policy, nil
+}
+
+// validate checks effects and patterns so that mistakes surface at startup.
+func (p *Policy) validate() error {
+	if p.Default == "" {
+		p.Default = Allow
+	}
+	if p.Default != Allow && p.Default != Deny {
+		return fmt.Errorf("default must be %q or %q, got %q", Allow, Deny, p.Default)
+	}
+
+	for i, rule := range p.Rules {
+		if rule.Effect != Allow && rule.Effect != Deny {
+			return fmt.Errorf("rule %d: effect must be %q or %q, got %q", i+1, Allow, Deny, rule.Effect)
+		}
+		patterns := [][]string{rule.Tools, rule.Namespaces, rule.Contexts, rule.Users, rule.Groups}
+		for _, values := range rule.Arguments {
+			patterns = append(patterns, values)
+		}
+		for _, list := range patterns {
+			for _, pattern := range list {
+				if _, err := path.Match(pattern, ""); err != nil {
+					return fmt.Errorf("rule %d: invalid pattern %q: %w", i+1, pattern, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Evaluate returns the decision of the first rule matching req, or the default.
+func (p *Policy) Evaluate(req Request) Decision {
+	for i, rule := range p.Rules {
+		if !rule.matches(req) {
+			continue
+		}
+		if rule.Effect == Allow {
+			return Decision{Allowed: true}
+		}
+		return Decision{Reason: rule.denialReason(i, req)}
+	}
+
+	if p.Default == Deny {
+		return Decision{Reason: fmt.Sprintf("%s is not allowed by any policy rule", describe(req))}
+	}
+	return Decision{Allowed: true}
+}
+
+// matches reports whether every constraint of the rule matches req.
+//
+// Calls without a namespace usually act on all namespaces, so a namespace
+// constraint matches them in deny rules but not in allow rules. That way a
+// namespace restriction can never be bypassed by omitting the namespace.
+func (r *Rule) matches(req Request) bool {
+	if len(r.Tools) > 0 && !matchAny(r.Tools, req.Tool) {
+		return false
+	}
+	if len(r.Kinds) > 0 && !matchKind(r.Kinds, req.Kind) {
+		return false
+	}
+	if len(r.Namespaces) > 0 {
+		if req.Namespace == "" {
+			if r.Effect == Allow {
+				return false
+			}
+		} else if !matchAny(r.Namespaces, req.Namespace) {
+			return false
+		}
+	}
+	if len(r.Contexts) > 0 && !matchAny(r.Contexts, req.Context) {
+		return false
+	}
+	if len(r.Users) > 0 && !matchAny(r.Users, req.User) {
+		return false
+	}
+	if len(r.Groups) > 0 {
+		matched := false
+		for _, group := range req.Groups {
+			if matchAny(r.Groups, group) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	for name, patterns := range r.Arguments {
+		value := ""
+		if arg, ok := req.Arguments[name]; ok && arg != nil {
+			value = fmt.Sprint(arg)
+		}
+		if !matchAny(patterns, value) {
+			return false
+		}
+	}
+	return true
+}
+
+// denialReason returns the message shown to the caller when rule i denies req.
+func (r *Rule) denialReason(i int, req Request) string {
+	if r.Message != "" {
+		return fmt.Sprintf("%s is denied by policy rule %d: %s", describe(req), i+1, r.Message)
+	}
+	return fmt.Sprintf("%s is denied by policy rule %d", describe(req), i+1)
+}
+
+// describe summarizes a request for denial messages.
+func describe(req Request) string {
+	description := req.Tool
+	if req.Kind != "" {
+		description += " on " + req.Kind
+	}
+	if req.Namespace != "" {
+		description += " in namespace " + req.Namespace
+	}
+	return description
+}
+
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, value); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func matchKind(kinds []string, kind string) bool {
+	for _, candidate := range kinds {
+		if candidate == "*" || (kind != "" && strings.EqualFold(candidate, kind)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,412 @@
+// Package redact masks secrets in tool results before they reach the model:
+// the data of Secrets, string values stored under keys that look sensitive,
+// such as Helm values named password or token, and the values of environment
+// variables that are sensitive or come from Secrets. In plain text, such as
+// logs and kubectl describe output, only "key: value" lines and "key=value"
+// assignments with a sensitive key are masked; secrets in free-form text or
+// spanning several lines are returned as they are.
+package redact
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"sigs.k8s.io/yaml"
+)
+
+// Masked replaces redacted values.
+const Masked = "[REDACTED]"
+
+// DefaultKeyPatterns are the patterns of keys whose string values are masked
+// unless others are configured.
+var DefaultKeyPatterns = []string{
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"credential",
+	"api[-_.]?key",
+	"access[-_.]?key",
+	"private[-_.]?key",
+}
+
+// secretObjectFields are the fields of Secret objects that never hold secret
+// data. Every other field, such as data and stringData, is masked.
+var secretObjectFields = map[string]bool{
+	"apiVersion": true,
+	"kind":       true,
+	"metadata":   true,
+	"type":       true,
+	"immutable":  true,
+}
+
+// secretSummaryFields are the fields of the summaries tools return about
+// Secrets, such as listResources items, applyManifests results and
+// diffResource results, that never hold secret data. Every other field, such
+// as a diff or the columns of a listResources item, is masked. None of these
+// fields can be named by the caller: listResources keeps the columns
+// requested with fields under "columns".
+var secretSummaryFields = map[string]bool{
+	"apiVersion": true,
+	"kind":       true,
+	"name":       true,
+	"namespace":  true,
+	"labels":     true,
+	"age":        true,
+	"owner":      true,
+	"document":   true,
+	"status":     true,
+	"message":    true,
+	"error":      true,
+	"conflicts":  true,
+}
+
+// lastAppliedAnnotation holds a copy of the applied manifest, including the
+// data of Secrets applied with kubectl apply.
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// documentSeparator splits multi-document YAML manifests.
+var documentSeparator = regexp.MustCompile(`(?m)^---[ \t]*$`)
+
+// textKeyValue matches "key: value" lines, as printed by kubectl describe,
+// and textAssignment "key=value" assignments, as in environment files,
+// logfmt logs and command-line flags.
+var (
+	textKeyValue   = regexp.MustCompile(`(?m)^([ \t]*(?:-[ \t]+)?)([\w.\-/]+)(:[ \t]+)(\S[^\r\n]*)`)
+	textAssignment = regexp.MustCompile(`(^|[\s,;&?])([\w.\-]+)(=)("[^"\r\n]*"|[^\s,;&]+)`)
+)
+
+// describePlaceholder matches the values kubectl describe prints instead of
+// a secret value, such as the size of a Secret's data or the Secret an
+// environment variable comes from.
+var describePlaceholder = regexp.MustCompile(`^(<none>|<unset>|<set to the key '[^']*' (in secret|of config map) '[^']*'>|\d+ bytes\s*$)`)
+
+// Redactor masks secrets in tool results.
+type Redactor struct {
+	keyPatterns []*regexp.Regexp
+}
+
+// New creates a Redactor that masks string values of keys matching one of
+// keyPatterns, which are case-insensitive regular expressions. Keys ending in
+// "name" or "ref", such as secretName or secretKeyRef, only refer to secrets
+// and are never masked.
+func New(keyPatterns []string) (*Redactor, error) {
+	redactor := &Redactor{}
+	for _, pattern := range keyPatterns {
+		compiled, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction key pattern %q: %w", pattern, err)
+		}
+		redactor.keyPatterns = append(redactor.keyPatterns, compiled)
+	}
+	return redactor, nil
+}
+
+// Middleware returns a tool handler middleware that redacts the text content
+// of every tool result.
+func (r *Redactor) Middleware() server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			result, err := next(ctx, request)
+			if result == nil {
+				return result, err
+			}
+
+			for i, content := range result.Content {
+				switch text := content.(type) {
+				case mcp.TextContent:
+					text.Text = r.RedactText(text.Text)
+					result.Content[i] = text
+				case *mcp.TextContent:
+					text.Text = r.RedactText(text.Text)
+				}
+			}
+			return result, err
+		}
+	}
+}
+
+// RedactText redacts a tool result. JSON results are redacted field by field,
+// and YAML results as Kubernetes manifests. In other text, such as logs or
+// kubectl describe output, the values of "key: value" lines and "key=value"
+// assignments with a sensitive key are masked. Text without anything to
+// redact is returned exactly as it was.
+func (r *Redactor) RedactText(text string) string {
+	trimmed := strings.TrimSpace(text)
+	if strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[") {
+		decoder := json.NewDecoder(strings.NewReader(trimmed))
+		decoder.UseNumber()
+		var value interface{}
+		if err := decoder.Decode(&value); err == nil && !decoder.More() {
+			if !r.redact(value) {
+				return text
+			}
+			var out bytes.Buffer
+			encoder := json.NewEncoder(&out)
+			encoder.SetEscapeHTML(false)
+			if err := encoder.Encode(value); err != nil {
+				return Masked
+			}
+			return strings.TrimSuffix(out.String(), "\n")
+		}
+	}
+
+	if redacted, changed := r.redactManifest(text); changed {
+		return redacted
+	}
+	return r.redactPlainText(text)
+}
+
+// redactPlainText masks the values of "key: value" lines and "key=value"
+// assignments whose key is sensitive.
+func (r *Redactor) redactPlainText(text string) string {
+	for _, pattern := range []*regexp.Regexp{textKeyValue, textAssignment} {
+		text = pattern.ReplaceAllStringFunc(text, func(match string) string {
+			parts := pattern.FindStringSubmatch(match)
+			prefix, key, separator, value := parts[1], parts[2], parts[3], parts[4]
+			if !r.isSensitiveKey(strings.TrimLeft(key, "-")) || strings.HasPrefix(value, Masked) || describePlaceholder.MatchString(value) {
+				return match
+			}
+			return prefix + key + separator + Masked
+		})
+	}
+	return text
+}
+
+// Redact masks secrets in a decoded JSON or YAML value, such as the arguments
+// of a tool call, in place and reports whether anything was masked.
+func (r *Redactor) Redact(value interface{}) bool {
+	return r.redact(value)
+}
+
+// redactManifest redacts the Kubernetes objects of a YAML manifest, such as
+// the rendered manifest of a Helm release. Only documents holding an object
+// with something to redact are rewritten; the comments leading them, such as
+// Helm's "# Source:" lines, are kept.
+func (r *Redactor) redactManifest(manifest string) (string, bool) {
+	documents := documentSeparator.Split(manifest, -1)
+	separators := documentSeparator.FindAllString(manifest, -1)
+
+	changed := false
+	for i, document := range documents {
+		var object map[string]interface{}
+		if err := yaml.Unmarshal([]byte(document), &object); err != nil {
+			continue
+		}
+		if _, ok := object["kind"].(string); !ok {
+			continue
+		}
+		if _, ok := object["apiVersion"].(string); !ok {
+			continue
+		}
+		if !r.redact(object) {
+			continue
+		}
+
+		out, err := yaml.Marshal(object)
+		if err != nil {
+			documents[i] = Masked + "\n"
+		} else {
+			documents[i] = leadingComments(document) + string(out)
+		}
+		changed = true
+	}
+	if !changed {
+		return manifest, false
+	}
+
+	var out strings.Builder
+	for i, document := range documents {
+		if i > 0 {
+			out.WriteString(separators[i-1])
+			if !strings.HasPrefix(document, "\n") {
+				out.WriteString("\n")
+			}
+		}
+		out.WriteString(document)
+	}
+	return out.String(), true
+}
+
+// leadingComments returns the comment and blank lines a YAML document starts with.
+func leadingComments(document string) string {
+	var out strings.Builder
+	for _, line := range strings.SplitAfter(document, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			break
+		}
+		out.WriteString(line)
+	}
+	return out.String()
+}
+
+// redact masks secrets in a decoded JSON or YAML value in place and reports
+// whether anything was masked.
+func (r *Redactor) redact(value interface{}) bool {
+	changed := false
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if v["kind"] == "Secret" {
+			changed = redactSecret(v)
+		}
+		if r.redactEnvVar(v) {
+			changed = true
+		}
+		for key, child := range v {
+			switch child := child.(type) {
+			case string:
+				if child == Masked {
+					continue
+				}
+				if key == "manifest" {
+					if redacted, ok := r.redactManifest(child); ok {
+						v[key] = redacted
+						changed = true
+					}
+					continue
+				}
+				if r.isSensitiveKey(key) {
+					v[key] = Masked
+					changed = true
+				}
+			default:
+				if r.redact(child) {
+					changed = true
+				}
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if r.redact(item) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+// redactSecret masks every field of a Secret, or of a tool's summary of a
+// Secret, that may hold its data, keeping the keys of maps such as data and
+// stringData, and its last applied configuration.
+func redactSecret(secret map[string]interface{}) bool {
+	safeFields := secretObjectFields
+	if isSecretSummary(secret) {
+		safeFields = secretSummaryFields
+	}
+
+	changed := false
+	for key, value := range secret {
+		if safeFields[key] {
+			continue
+		}
+		switch value.(type) {
+		case bool, json.Number, float64, int64, nil:
+			continue
+		}
+		if masked := maskValues(value); masked != nil {
+			secret[key] = masked
+			changed = true
+		}
+	}
+
+	annotations, _ := nestedMap(secret, "metadata", "annotations")
+	if applied, ok := annotations[lastAppliedAnnotation].(string); ok && applied != Masked {
+		annotations[lastAppliedAnnotation] = Masked
+		changed = true
+	}
+	return changed
+}
+
+// isSecretSummary reports whether secret is a tool's summary of a Secret
+// rather than the object itself: summaries hold the name at the top level,
+// objects in their metadata.
+func isSecretSummary(secret map[string]interface{}) bool {
+	if _, ok := secret["metadata"]; ok {
+		return false
+	}
+	_, named := secret["name"].(string)
+	return named
+}
+
+// maskValues masks a map's values while keeping its keys, and any other value
+// entirely. It returns nil if value is already masked.
+func maskValues(value interface{}) interface{} {
+	data, ok := value.(map[string]interface{})
+	if !ok {
+		if value == Masked {
+			return nil
+		}
+		return Masked
+	}
+
+	masked := false
+	for key, item := range data {
+		if item != Masked {
+			data[key] = Masked
+			masked = true
+		}
+	}
+	if !masked {
+		return nil
+	}
+	return data
+}
+
+// redactEnvVar masks the value of a name/value pair, such as a container's
+// environment variable, whose name looks sensitive or whose value comes from
+// a Secret.
+func (r *Redactor) redactEnvVar(envVar map[string]interface{}) bool {
+	name, ok := envVar["name"].(string)
+	if !ok {
+		return false
+	}
+	value, ok := envVar["value"].(string)
+	if !ok || value == Masked {
+		return false
+	}
+
+	_, fromSecret := nestedMap(envVar, "valueFrom", "secretKeyRef")
+	if !fromSecret && !r.matchesKeyPattern(name) {
+		return false
+	}
+	envVar["value"] = Masked
+	return true
+}
+
+// isSensitiveKey reports whether the string value of a key is masked.
+func (r *Redactor) isSensitiveKey(key string) bool {
+	lowered := strings.ToLower(key)
+	if strings.HasSuffix(lowered, "name") || strings.HasSuffix(lowered, "ref") {
+		return false
+	}
+	return r.matchesKeyPattern(key)
+}
+
+// matchesKeyPattern reports whether key matches one of the key patterns.
+func (r *Redactor) matchesKeyPattern(key string) bool {
+	for _, pattern := range r.keyPatterns {
+		if pattern.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// nestedMap returns the map at path in value.
+func nestedMap(value map[string]interface{}, path ...string) (map[string]interface{}, bool) {
+	for _, key := range path {
+		next, ok := value[key].(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		value = next
+	}
+	return value, true
+}
@@ -0,0 +1,125 @@
+package redact
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRedactTextSecrets(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "Secret object keeps only its safe fields",
+			text: `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"db","labels":{"app":"db"}},"type":"Opaque","immutable":true,` +
+				`"data":{"password":"aHVudGVyMg=="},"stringData":{"user":"admin"},"message":"hunter2","status":"hunter2"}`,
+			want: `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"db","labels":{"app":"db"}},"type":"Opaque","immutable":true,` +
+				`"data":{"password":"[REDACTED]"},"stringData":{"user":"[REDACTED]"},"message":"[REDACTED]","status":"[REDACTED]"}`,
+		},
+		{
+			name: "Secret list with fields masks every column",
+			text: `{"items":[{"name":"db","kind":"Secret","namespace":"default","columns":{"name":"aHVudGVyMg==","kind":"Opaque","labels":"aHVudGVyMg==","count":2,"missing":null}}]}`,
+			want: `{"items":[{"name":"db","kind":"Secret","namespace":"default","columns":{"name":"[REDACTED]","kind":"[REDACTED]","labels":"[REDACTED]","count":"[REDACTED]","missing":"[REDACTED]"}}]}`,
+		},
+		{
+			name: "default Secret list item",
+			text: `{"items":[{"name":"db","kind":"Secret","namespace":"default","labels":{"app":"db"},"age":"3d","owner":"SealedSecret/db"}]}`,
+			want: `{"items":[{"name":"db","kind":"Secret","namespace":"default","labels":{"app":"db"},"age":"3d","owner":"SealedSecret/db"}]}`,
+		},
+		{
+			name: "columns of other kinds are kept",
+			text: `{"items":[{"name":"web","kind":"Pod","namespace":"default","columns":{"image":"nginx","node":"worker-1"}}]}`,
+			want: `{"items":[{"name":"web","kind":"Pod","namespace":"default","columns":{"image":"nginx","node":"worker-1"}}]}`,
+		},
+		{
+			name: "diff of a Secret",
+			text: `{"kind":"Secret","name":"db","namespace":"default","exists":true,"changed":true,"diff":"-  password: aHVudGVyMg==\n+  password: c2VjcmV0\n"}`,
+			want: `{"kind":"Secret","name":"db","namespace":"default","exists":true,"changed":true,"diff":"[REDACTED]"}`,
+		},
+		{
+			name: "apply results of a Secret",
+			text: `[{"document":1,"apiVersion":"v1","kind":"Secret","name":"db","status":"failed","error":"forbidden",` +
+				`"conflicts":[{"field":".data.password","manager":"kubectl","message":"conflict with kubectl"}]}]`,
+			want: `[{"document":1,"apiVersion":"v1","kind":"Secret","name":"db","status":"failed","error":"forbidden",` +
+				`"conflicts":[{"field":".data.password","manager":"kubectl","message":"conflict with kubectl"}]}]`,
+		},
+		{
+			name: "Secret sections without metadata",
+			text: `{"apiVersion":"v1","kind":"Secret","data":{"password":"aHVudGVyMg=="}}`,
+			want: `{"apiVersion":"v1","kind":"Secret","data":{"password":"[REDACTED]"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redactor, err := New(DefaultKeyPatterns)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got, want interface{}
+			if err := json.Unmarshal([]byte(redactor.RedactText(tt.text)), &got); err != nil {
+				t.Fatalf("RedactText() returned invalid JSON: %v", err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				gotJSON, _ := json.Marshal(got)
+				t.Errorf("RedactText() =\n%s\nwant\n%s", gotJSON, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactTextPlain(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "describe environment",
+			text: "    Environment:\n      DB_PASSWORD:  hunter2\n      DB_TOKEN:     <set to the key 'token' in secret 'db'>  Optional: false\n      LOG_LEVEL:    debug\n",
+			want: "    Environment:\n      DB_PASSWORD:  [REDACTED]\n      DB_TOKEN:     <set to the key 'token' in secret 'db'>  Optional: false\n      LOG_LEVEL:    debug\n",
+		},
+		{
+			name: "describe Secret data sizes",
+			text: "Data\n====\npassword:  7 bytes\ntoken:     12 bytes\n",
+			want: "Data\n====\npassword:  7 bytes\ntoken:     12 bytes\n",
+		},
+		{
+			name: "references are kept",
+			text: "    SecretName:  db\n    secretKeyRef: db\n",
+			want: "    SecretName:  db\n    secretKeyRef: db\n",
+		},
+		{
+			name: "assignments",
+			text: "export API_KEY=abc123\nlevel=info msg=\"login\" token=\"xyz\",user=alice\nargs: --db-password=hunter2 --port=5432\n",
+			want: "export API_KEY=[REDACTED]\nlevel=info msg=\"login\" token=[REDACTED],user=alice\nargs: --db-password=[REDACTED] --port=5432\n",
+		},
+		{
+			name: "key in the middle of a line",
+			text: "2024-05-01T12:00:00Z password: hunter2\n",
+			want: "2024-05-01T12:00:00Z password: hunter2\n",
+		},
+		{
+			name: "nothing to redact",
+			text: "Name:         web\nNamespace:    default\n",
+			want: "Name:         web\nNamespace:    default\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redactor, err := New(DefaultKeyPatterns)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := redactor.RedactText(tt.text); got != tt.want {
+				t.Errorf("RedactText(%q) =\n%q\nwant\n%q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
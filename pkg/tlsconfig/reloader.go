@@ -0,0 +1,159 @@
+// Package tlsconfig builds the TLS configuration of the HTTP transports and
+// reloads rotated certificates from disk without restarting the server.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// reloadCheckInterval limits how often the files are checked for changes.
+const reloadCheckInterval = 10 * time.Second
+
+// Options configures TLS serving.
+type Options struct {
+	// CertFile and KeyFile are the server certificate and private key.
+	CertFile string
+	KeyFile  string
+	// ClientCAFile is a PEM bundle of CAs used to verify client certificates.
+	// Client certificates are not requested when it is empty.
+	ClientCAFile string
+	// RequireClientCert rejects TLS handshakes without a valid client certificate.
+	RequireClientCert bool
+}
+
+// Reloader serves the current certificate and client CA pool, reloading them
+// when any of the files on disk changes.
+type Reloader struct {
+	options Options
+
+	lock      sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTimes  map[string]time.Time
+	lastCheck time.Time
+}
+
+// NewReloader loads the certificate, key and client CA bundle.
+func NewReloader(options Options) (*Reloader, error) {
+	if options.CertFile == "" || options.KeyFile == "" {
+		return nil, fmt.Errorf("both a TLS certificate and key are required")
+	}
+	if options.RequireClientCert && options.ClientCAFile == "" {
+		return nil, fmt.Errorf("requiring client certificates needs a client CA file")
+	}
+
+	r := &Reloader{options: options}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// TLSConfig returns a server TLS configuration backed by the reloader.
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.reloadIfChanged()
+
+			r.lock.RLock()
+			defer r.lock.RUnlock()
+
+			config := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.cert},
+				NextProtos:   []string{"h2", "http/1.1"},
+			}
+			if r.clientCAs != nil {
+				config.ClientCAs = r.clientCAs
+				config.ClientAuth = tls.VerifyClientCertIfGiven
+				if r.options.RequireClientCert {
+					config.ClientAuth = tls.RequireAndVerifyClientCert
+				}
+			}
+			return config, nil
+		},
+	}
+}
+
+// reloadIfChanged reloads the files if any of them changed since the last load.
+// A failed reload keeps serving the previous certificate.
+func (r *Reloader) reloadIfChanged() {
+	r.lock.RLock()
+	due := time.Since(r.lastCheck) >= reloadCheckInterval
+	r.lock.RUnlock()
+	if !due {
+		return
+	}
+
+	changed := false
+	modTimes := r.currentModTimes()
+	r.lock.Lock()
+	r.lastCheck = time.Now()
+	for file, modTime := range modTimes {
+		if !modTime.Equal(r.modTimes[file]) {
+			changed = true
+		}
+	}
+	r.lock.Unlock()
+
+	if changed {
+		if err := r.load(); err != nil {
+			fmt.Printf("Failed to reload TLS certificates, keeping the previous ones: %v\n", err)
+			return
+		}
+		fmt.Println("Reloaded TLS certificates")
+	}
+}
+
+// load reads the certificate, key and client CA bundle from disk.
+func (r *Reloader) load() error {
+	modTimes := r.currentModTimes()
+
+	cert, err := tls.LoadX509KeyPair(r.options.CertFile, r.options.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if r.options.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.options.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read client CA file: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("client CA file %s contains no certificates", r.options.ClientCAFile)
+		}
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.modTimes = modTimes
+	r.lastCheck = time.Now()
+	return nil
+}
+
+// currentModTimes returns the modification times of the configured files.
+// Files that cannot be read are reported with a zero time.
+func (r *Reloader) currentModTimes() map[string]time.Time {
+	modTimes := make(map[string]time.Time)
+	for _, file := range []string{r.options.CertFile, r.options.KeyFile, r.options.ClientCAFile} {
+		if file == "" {
+			continue
+		}
+		if info, err := os.Stat(file); err == nil {
+			modTimes[file] = info.ModTime()
+		} else {
+			modTimes[file] = time.Time{}
+		}
+	}
+	return modTimes
+}
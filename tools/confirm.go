@@ -0,0 +1,10 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// withConfirmationToken adds the confirmationToken parameter of destructive tools.
+func withConfirmationToken() mcp.ToolOption {
+	return mcp.WithString("confirmationToken", mcp.Description("Token returned by a previous call with the same arguments, confirming the operation after its impact was reviewed. Omit it to get the impact summary and a token."))
+}
@@ -0,0 +1,27 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// withContext adds the optional kubeconfig context parameter shared by all
+// cluster-facing tools.
+func withContext() mcp.ToolOption {
+	return mcp.WithString("context", mcp.Description("The kubeconfig context to use (defaults to the current context)"))
+}
+
+// ListContextsTool creates a tool for listing the available kubeconfig contexts.
+func ListContextsTool() mcp.Tool {
+	return mcp.NewTool(
+		"listContexts",
+		mcp.WithDescription("List all contexts defined in the kubeconfig, including their cluster, user and default namespace"),
+	)
+}
+
+// GetCurrentContextTool creates a tool for getting the current kubeconfig context.
+func GetCurrentContextTool() mcp.Tool {
+	return mcp.NewTool(
+		"getCurrentContext",
+		mcp.WithDescription("Get the current kubeconfig context, used when a tool is called without a context"),
+	)
+}
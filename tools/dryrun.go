@@ -0,0 +1,10 @@
+package tools
+
+import (
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// withDryRun adds the optional dryRun parameter shared by all mutating tools.
+func withDryRun() mcp.ToolOption {
+	return mcp.WithBoolean("dryRun", mcp.Description("Validate the change and return what would be changed, without applying it"))
+}
@@ -13,6 +13,8 @@ func HelmInstallTool() mcp.Tool {
 		mcp.WithString("namespace", mcp.Description("Kubernetes namespace for the release")),
 		mcp.WithString("repoURL", mcp.Description("Helm repository URL (optional)")),
 		mcp.WithObject("values", mcp.Description("Values to override in the chart")),
+		withDryRun(),
+		withContext(),
 	)
 }
 
@@ -25,15 +27,20 @@ func HelmUpgradeTool() mcp.Tool {
 		mcp.WithString("namespace", mcp.Required(), mcp.Description("Kubernetes namespace of the release")),
 		mcp.WithObject("values", mcp.Required(), mcp.Description("Values to override in the chart")),
 		mcp.WithObject("repoURL", mcp.Required(), mcp.Description("URL of the Helm repository")),
+		withDryRun(),
+		withContext(),
 	)
 }
 
 // HelmUninstallTool returns the MCP tool definition for uninstalling Helm releases
 func HelmUninstallTool() mcp.Tool {
 	return mcp.NewTool("helmUninstall",
-		mcp.WithDescription("Uninstall a Helm release from the Kubernetes cluster"),
+		mcp.WithDescription("Uninstall a Helm release from the Kubernetes cluster. The server may require confirmation: the first call then returns the resources that would be removed and a confirmationToken, and only a second identical call with that token uninstalls the release."),
 		mcp.WithString("releaseName", mcp.Required(), mcp.Description("Name of the Helm release to uninstall")),
 		mcp.WithString("namespace", mcp.Required(), mcp.Description("Kubernetes namespace of the release")),
+		withDryRun(),
+		withConfirmationToken(),
+		withContext(),
 	)
 }
 
@@ -42,6 +49,7 @@ func HelmListTool() mcp.Tool {
 	return mcp.NewTool("helmList",
 		mcp.WithDescription("List all Helm releases in the cluster or a specific namespace"),
 		mcp.WithString("namespace", mcp.Required(), mcp.Description("Kubernetes namespace to list releases from (empty for all namespaces)")),
+		withContext(),
 	)
 }
 
@@ -51,6 +59,7 @@ func HelmGetTool() mcp.Tool {
 		mcp.WithDescription("Get details of a specific Helm release"),
 		mcp.WithString("releaseName", mcp.Required(), mcp.Description("Name of the Helm release")),
 		mcp.WithString("namespace", mcp.Required(), mcp.Description("Kubernetes namespace of the release")),
+		withContext(),
 	)
 }
 
@@ -60,6 +69,7 @@ func HelmHistoryTool() mcp.Tool {
 		mcp.WithDescription("Get the history of a Helm release"),
 		mcp.WithString("releaseName", mcp.Required(), mcp.Description("Name of the Helm release")),
 		mcp.WithString("namespace", mcp.Required(), mcp.Description("Kubernetes namespace of the release")),
+		withContext(),
 	)
 }
 
@@ -70,6 +80,8 @@ func HelmRollbackTool() mcp.Tool {
 		mcp.WithString("releaseName", mcp.Required(), mcp.Description("Name of the Helm release to rollback")),
 		mcp.WithString("namespace", mcp.Required(), mcp.Description("Kubernetes namespace of the release")),
 		mcp.WithNumber("revision", mcp.Required(), mcp.Description("Revision number to rollback to (0 for previous)")),
+		withDryRun(),
+		withContext(),
 	)
 }
 
@@ -78,11 +90,13 @@ func HelmRepoAddTool() mcp.Tool {
 		mcp.WithDescription("Add a Helm repository"),
 		mcp.WithString("repoName", mcp.Required(), mcp.Description("Name of the Helm repository")),
 		mcp.WithString("repoURL", mcp.Required(), mcp.Description("URL of the Helm repository")),
+		withContext(),
 	)
 }
 
 func HelmRepoListTool() mcp.Tool {
 	return mcp.NewTool("helmRepoList",
 		mcp.WithDescription("List all Helm repositories"),
+		withContext(),
 	)
 }
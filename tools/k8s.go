@@ -23,6 +23,7 @@ func GetAPIResourcesTool() mcp.Tool {
 			"The function is designed to be used as a handler for the mcp tool"),
 		mcp.WithBoolean("includeNamespaceScoped", mcp.Description("Include namespace scoped resources")),
 		mcp.WithBoolean("includeClusterScoped", mcp.Description("Include cluster scoped resources")),
+		withContext(),
 	)
 
 }
@@ -33,11 +34,16 @@ func GetAPIResourcesTool() mcp.Tool {
 func ListResourcesTool() mcp.Tool {
 	return mcp.NewTool(
 		"listResources",
-		mcp.WithDescription("List all resources in the Kubernetes cluster of a specific type"),
-		mcp.WithString("Kind", mcp.Required(), mcp.Description("The type of resource to list")),
+		mcp.WithDescription("List resources in the Kubernetes cluster of a specific type. Results are paginated: when more resources are available, the response contains a continue token to pass to the next call"),
+		mcp.WithString("Kind", mcp.Required(), mcp.Description("The type of resource to list (kind, plural or short name, optionally qualified with the API group, e.g. Deployment, deploy, ingresses.networking.k8s.io)")),
 		mcp.WithString("namespace", mcp.Description("The namespace to list resources in")),
 		mcp.WithString("labelSelector", mcp.Description("A label selector to filter resources")),
 		mcp.WithString("fieldSelector", mcp.Description("A field selector to filter resources")),
+		mcp.WithString("fields", mcp.Description("Comma-separated columns to return for each resource instead of the default summary, as NAME:JSONPATH like kubectl custom-columns, e.g. 'image:.spec.containers[*].image,node:.spec.nodeName' (the name may be omitted); the values are returned under 'columns'")),
+		mcp.WithNumber("limit", mcp.Description("The maximum number of resources to return; the server caps it at its configured maximum")),
+		mcp.WithString("continue", mcp.Description("The continue token returned with the previous page, to get the next page of resources with the same arguments")),
+		mcp.WithString("output", mcp.Description("Output format: 'json' (default) for resource summaries, or 'table' for the compact columns and rows kubectl get prints, including printer columns of custom resources (cannot be combined with fields)")),
+		withContext(),
 	)
 }
 
@@ -48,9 +54,13 @@ func GetResourcesTool() mcp.Tool {
 	return mcp.NewTool(
 		"getResource",
 		mcp.WithDescription("Get a specific resource in the Kubernetes cluster"),
-		mcp.WithString("kind", mcp.Required(), mcp.Description("The type of resource to get")),
+		mcp.WithString("kind", mcp.Required(), mcp.Description("The type of resource to get (kind, plural or short name, optionally qualified with the API group, e.g. Deployment, deploy, ingresses.networking.k8s.io)")),
 		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the resource to get")),
 		mcp.WithString("namespace", mcp.Description("The namespace of the resource")),
+		mcp.WithString("output", mcp.Description("Output format: 'json' (default) or 'yaml' for the resource, or 'table' for the compact columns kubectl get prints")),
+		mcp.WithBoolean("strip", mcp.Description("Remove metadata.managedFields and noisy annotations such as kubectl.kubernetes.io/last-applied-configuration (default: true)")),
+		mcp.WithString("sections", mcp.Description("Comma-separated top-level fields to return besides apiVersion and kind, e.g. 'spec' or 'metadata,status' (default: all)")),
+		withContext(),
 	)
 }
 
@@ -60,10 +70,11 @@ func GetResourcesTool() mcp.Tool {
 func DescribeResourcesTool() mcp.Tool {
 	return mcp.NewTool(
 		"describeResource",
-		mcp.WithDescription("Describe a resource in the Kubernetes cluster based on given kind and name"),
-		mcp.WithString("Kind", mcp.Required(), mcp.Description("The type of resource to describe")),
+		mcp.WithDescription("Describe a resource in the Kubernetes cluster based on given kind and name, like kubectl describe: a human-readable summary including its events, owner, and kind-specific details such as container states and last termination reasons of Pods, ReplicaSets and conditions of Deployments, or allocated resources of Nodes"),
+		mcp.WithString("Kind", mcp.Required(), mcp.Description("The type of resource to describe (kind, plural or short name, optionally qualified with the API group, e.g. Deployment, deploy, ingresses.networking.k8s.io)")),
 		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the resource to describe")),
 		mcp.WithString("namespace", mcp.Description("The namespace of the resource")),
+		withContext(),
 	)
 }
 
@@ -73,10 +84,101 @@ func DescribeResourcesTool() mcp.Tool {
 func GetPodsLogsTools() mcp.Tool {
 	return mcp.NewTool(
 		"getPodsLogs",
-		mcp.WithDescription("Get logs of a specific pod in the Kubernetes cluster"),
+		mcp.WithDescription("Get logs of a specific pod in the Kubernetes cluster. To find out why a container crashed, get the logs of its previous instance with previous: true"),
 		mcp.WithString("Name", mcp.Required(), mcp.Description("The name of the pod to get logs from")),
-		mcp.WithString("containerName", mcp.Description("The name of the container to get logs from")),
+		mcp.WithString("containerName", mcp.Description("The name of the container to get logs from, which may be an init or ephemeral container (default: all app containers)")),
 		mcp.WithString("namespace", mcp.Required(), mcp.Description("The namespace of the pod")),
+		mcp.WithNumber("tailLines", mcp.Description("The number of lines from the end of the logs to return (default: 100, -1 for all lines)")),
+		mcp.WithNumber("sinceSeconds", mcp.Description("Only return logs newer than this many seconds")),
+		mcp.WithString("sinceTime", mcp.Description("Only return logs after this RFC3339 timestamp, e.g. 2025-01-01T12:00:00Z (cannot be combined with sinceSeconds)")),
+		mcp.WithBoolean("previous", mcp.Description("Return the logs of the previous, terminated instance of the container, e.g. the one that crashed")),
+		mcp.WithBoolean("timestamps", mcp.Description("Prefix every line with its RFC3339 timestamp")),
+		mcp.WithNumber("limitBytes", mcp.Description("The maximum number of bytes of logs to return per container")),
+		mcp.WithBoolean("initContainers", mcp.Description("Also return the logs of init containers when no containerName is given")),
+		mcp.WithBoolean("ephemeralContainers", mcp.Description("Also return the logs of ephemeral (debug) containers when no containerName is given")),
+		withLogFilters(),
+		withContext(),
+	)
+}
+
+// withLogFilters adds the parameters that filter log lines on the server.
+func withLogFilters() mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		for _, option := range []mcp.ToolOption{
+			mcp.WithString("grep", mcp.Description("Only return lines matching this regular expression (RE2 syntax, e.g. 'timeout|refused' or '(?i)error'). With grep or level, all lines are searched unless tailLines is set, and the last matching lines are returned with the match count")),
+			mcp.WithBoolean("invert", mcp.Description("Return the lines not matching grep instead")),
+			mcp.WithString("level", mcp.Description("Only return lines logged at this level or above: debug, info, warn, error or fatal. Recognizes JSON logs with a level or severity field, logfmt (level=error), klog (E0101 ...) and upper-case level names")),
+			mcp.WithNumber("contextLines", mcp.Description("The number of lines to return before and after each matching line")),
+		} {
+			option(tool)
+		}
+	}
+}
+
+// GetWorkloadLogsTool creates a tool for getting the logs of all pods of a
+// workload or label selector.
+func GetWorkloadLogsTool() mcp.Tool {
+	return mcp.NewTool(
+		"getWorkloadLogs",
+		mcp.WithDescription("Get the logs of all pods of a workload (Deployment, StatefulSet, DaemonSet, ReplicaSet, Job or Service) or of the pods matching a label selector, like kubectl logs -l or stern. Lines of all pods are interleaved by time and prefixed with [pod/container]"),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("The namespace of the workload or pods")),
+		mcp.WithString("kind", mcp.Description("The kind of the workload, e.g. Deployment or sts (requires name; cannot be combined with labelSelector)")),
+		mcp.WithString("name", mcp.Description("The name of the workload")),
+		mcp.WithString("labelSelector", mcp.Description("A label selector for the pods, e.g. app=web, instead of a workload")),
+		mcp.WithString("containerName", mcp.Description("The name of the container to get logs from in every pod (default: all app containers)")),
+		mcp.WithNumber("tailLines", mcp.Description("The number of lines from the end of each container's logs to return (default: 20, -1 for all lines)")),
+		mcp.WithNumber("sinceSeconds", mcp.Description("Only return logs newer than this many seconds")),
+		mcp.WithString("sinceTime", mcp.Description("Only return logs after this RFC3339 timestamp, e.g. 2025-01-01T12:00:00Z (cannot be combined with sinceSeconds)")),
+		mcp.WithBoolean("previous", mcp.Description("Return the logs of the previous, terminated instance of each container, e.g. the ones that crashed")),
+		mcp.WithBoolean("timestamps", mcp.Description("Prefix every line with its RFC3339 timestamp")),
+		mcp.WithNumber("limitBytes", mcp.Description("The maximum number of bytes of logs to return per container")),
+		mcp.WithBoolean("initContainers", mcp.Description("Also return the logs of init containers when no containerName is given")),
+		mcp.WithBoolean("ephemeralContainers", mcp.Description("Also return the logs of ephemeral (debug) containers when no containerName is given")),
+		withLogFilters(),
+		withContext(),
+	)
+}
+
+// FollowPodLogsTool creates a tool for following the logs of a pod live.
+func FollowPodLogsTool() mcp.Tool {
+	return mcp.NewTool(
+		"followPodLogs",
+		mcp.WithDescription("Follow the logs of a pod live, like kubectl logs -f, for a bounded duration or until a line matches a pattern. New lines are pushed to the client as progress notifications if the request has a progress token, or as log messages otherwise. Returns a summary with the last lines once following stops, e.g. to wait for a rollout to log 'ready' or to watch a reproduction"),
+		mcp.WithString("Name", mcp.Required(), mcp.Description("The name of the pod to follow logs of")),
+		mcp.WithString("containerName", mcp.Description("The name of the container to follow, required if the pod has several containers")),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("The namespace of the pod")),
+		mcp.WithNumber("durationSeconds", mcp.Description("How long to follow the logs in seconds (default: 30, maximum: 300)")),
+		mcp.WithString("until", mcp.Description("Stop following after the first line matching this regular expression (RE2 syntax), e.g. 'Started|ready'")),
+		mcp.WithNumber("maxLines", mcp.Description("Stop following after this many matching lines (default: 500)")),
+		mcp.WithNumber("tailLines", mcp.Description("The number of lines from before the call to start with (default: 0, -1 for all lines)")),
+		mcp.WithNumber("sinceSeconds", mcp.Description("Start with the lines of the last this many seconds instead")),
+		mcp.WithBoolean("timestamps", mcp.Description("Prefix every line with its RFC3339 timestamp")),
+		withLogFilters(),
+		withContext(),
+	)
+}
+
+// SummarizeLogsTool creates a tool for summarizing the logs of a pod as
+// templates of similar lines.
+func SummarizeLogsTool() mcp.Tool {
+	return mcp.NewTool(
+		"summarizeLogs",
+		mcp.WithDescription("Summarize large logs of a pod: lines are clustered into templates, with numbers, UUIDs, IP addresses and timestamps replaced by placeholders, and the most frequent templates are returned with their counts, first and last occurrence and an example line. Use it to see the shape of long logs before fetching specific lines with getPodsLogs"),
+		mcp.WithString("Name", mcp.Required(), mcp.Description("The name of the pod to summarize logs of")),
+		mcp.WithString("containerName", mcp.Description("The name of the container to summarize logs of, which may be an init or ephemeral container (default: all app containers together)")),
+		mcp.WithString("namespace", mcp.Required(), mcp.Description("The namespace of the pod")),
+		mcp.WithNumber("maxTemplates", mcp.Description("The number of most frequent templates to return (default: 20)")),
+		mcp.WithNumber("tailLines", mcp.Description("The number of lines from the end of each container's logs to summarize (default: 50000, -1 for all lines)")),
+		mcp.WithNumber("sinceSeconds", mcp.Description("Only summarize logs newer than this many seconds")),
+		mcp.WithString("sinceTime", mcp.Description("Only summarize logs after this RFC3339 timestamp, e.g. 2025-01-01T12:00:00Z (cannot be combined with sinceSeconds)")),
+		mcp.WithBoolean("previous", mcp.Description("Summarize the logs of the previous, terminated instance of the container, e.g. the one that crashed")),
+		mcp.WithNumber("limitBytes", mcp.Description("The maximum number of bytes of logs to read per container")),
+		mcp.WithBoolean("initContainers", mcp.Description("Also summarize the logs of init containers when no containerName is given")),
+		mcp.WithBoolean("ephemeralContainers", mcp.Description("Also summarize the logs of ephemeral (debug) containers when no containerName is given")),
+		mcp.WithString("grep", mcp.Description("Only summarize lines matching this regular expression (RE2 syntax, e.g. 'timeout|refused' or '(?i)error')")),
+		mcp.WithBoolean("invert", mcp.Description("Summarize the lines not matching grep instead")),
+		mcp.WithString("level", mcp.Description("Only summarize lines logged at this level or above: debug, info, warn, error or fatal")),
+		withContext(),
 	)
 }
 
@@ -87,6 +189,7 @@ func GetNodeMetricsTools() mcp.Tool {
 		"getNodeMetrics",
 		mcp.WithDescription("Get resource usage of a specific node in the Kubernetes cluster"),
 		mcp.WithString("Name", mcp.Required(), mcp.Description("The name of the node to get resource usage from")),
+		withContext(),
 	)
 }
 
@@ -99,6 +202,7 @@ func GetPodMetricsTool() mcp.Tool {
 		mcp.WithDescription("Get CPU and Memory metrics for a specific pod"),
 		mcp.WithString("namespace", mcp.Required(), mcp.Description("The namespace of the pod")),
 		mcp.WithString("podName", mcp.Required(), mcp.Description("The name of the pod")),
+		withContext(),
 	)
 }
 
@@ -111,6 +215,7 @@ func GetEventsTool() mcp.Tool {
 		mcp.WithDescription("Get events in the Kubernetes cluster"),
 		mcp.WithString("namespace", mcp.Description("The namespace to get events from")),
 		mcp.WithString("labelSelector", mcp.Description("A label selector to filter events")),
+		withContext(),
 	)
 }
 
@@ -122,6 +227,8 @@ func CreateOrUpdateResourceJSONTool() mcp.Tool {
 		mcp.WithString("kind", mcp.Required(), mcp.Description("The type of resource to create")),
 		mcp.WithString("namespace", mcp.Description("The namespace of the resource")),
 		mcp.WithString("manifest", mcp.Required(), mcp.Description("The manifest of the resource to create")),
+		withDryRun(),
+		withContext(),
 	)
 }
 
@@ -133,6 +240,56 @@ func CreateOrUpdateResourceYAMLTool() mcp.Tool {
 		mcp.WithString("kind", mcp.Description("The type of resource to create (optional, will be inferred from YAML manifest if not provided)")),
 		mcp.WithString("namespace", mcp.Description("The namespace of the resource (overrides namespace in YAML manifest if provided)")),
 		mcp.WithString("yamlManifest", mcp.Required(), mcp.Description("The YAML manifest of the resource to create or update. Must be valid Kubernetes YAML format.")),
+		withDryRun(),
+		withContext(),
+	)
+}
+
+// RefreshDiscoveryTool creates a tool definition for refreshing the cached API discovery
+func RefreshDiscoveryTool() mcp.Tool {
+	return mcp.NewTool(
+		"refreshDiscovery",
+		mcp.WithDescription("Drop the server's cached list of API resources and fetch it again. Use it when a resource type that should exist, such as one from a newly installed CRD, is reported as not found. Returns the number of API groups and resources found."),
+		withContext(),
+	)
+}
+
+// DiffResourceTool creates a tool definition for previewing changes to resources
+func DiffResourceTool() mcp.Tool {
+	return mcp.NewTool(
+		"diffResource",
+		mcp.WithDescription("Preview what applying a manifest would change. Runs a server-side dry-run apply and returns a unified YAML diff between the live object and the result, without managed fields and status. Fields owned by other field managers, which applyResource would reject unless forced, are reported as conflicts. Nothing is changed in the cluster."),
+		mcp.WithString("manifest", mcp.Required(), mcp.Description("The YAML or JSON manifest of the resource to preview")),
+		mcp.WithString("kind", mcp.Description("The type of resource (optional, will be inferred from the manifest if not provided)")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the resource (overrides namespace in the manifest if provided)")),
+		withContext(),
+	)
+}
+
+// ApplyResourceTool creates a tool definition for applying resources with server-side apply
+func ApplyResourceTool() mcp.Tool {
+	return mcp.NewTool(
+		"applyResource",
+		mcp.WithDescription("Create or update a resource with server-side apply. The server records itself as the field manager of the applied fields, removes fields it previously applied that are no longer in the manifest, and rejects the apply with the conflicting fields and their owners when other field managers own fields in the manifest, unless force is set."),
+		mcp.WithString("manifest", mcp.Required(), mcp.Description("The complete YAML or JSON manifest of the resource, including apiVersion, kind and metadata.name")),
+		mcp.WithString("kind", mcp.Description("The type of resource (optional, will be inferred from the manifest if not provided)")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the resource (overrides namespace in the manifest if provided)")),
+		mcp.WithBoolean("force", mcp.Description("Take ownership of fields owned by other field managers instead of failing with a conflict (default: false)")),
+		withDryRun(),
+		withContext(),
+	)
+}
+
+// ApplyManifestsTool creates a tool definition for applying multi-document manifests
+func ApplyManifestsTool() mcp.Tool {
+	return mcp.NewTool(
+		"applyManifests",
+		mcp.WithDescription("Apply every object of a multi-document YAML manifest ('---' separated) or a List with server-side apply. Each object's type is taken from its apiVersion and kind. Namespaces and CustomResourceDefinitions are applied first and workloads after their configuration. Returns the status of each object (created, configured, unchanged or failed); a failed object does not stop the others."),
+		mcp.WithString("manifest", mcp.Required(), mcp.Description("The YAML or JSON manifest; every object needs apiVersion, kind and metadata.name")),
+		mcp.WithString("namespace", mcp.Description("The namespace of the namespaced objects (overrides the namespaces in the manifest if provided; objects without one default to 'default')")),
+		mcp.WithBoolean("force", mcp.Description("Take ownership of fields owned by other field managers instead of failing with a conflict (default: false)")),
+		withDryRun(),
+		withContext(),
 	)
 }
 
@@ -140,10 +297,13 @@ func CreateOrUpdateResourceYAMLTool() mcp.Tool {
 func DeleteResourceTool() mcp.Tool {
 	return mcp.NewTool(
 		"deleteResource",
-		mcp.WithDescription("Delete a resource in the Kubernetes cluster"),
-		mcp.WithString("kind", mcp.Required(), mcp.Description("The type of resource to delete")),
+		mcp.WithDescription("Delete a resource in the Kubernetes cluster. The server may require confirmation: the first call then returns the resource and its dependents that would be deleted and a confirmationToken, and only a second identical call with that token deletes it."),
+		mcp.WithString("kind", mcp.Required(), mcp.Description("The type of resource to delete (kind, plural or short name, optionally qualified with the API group, e.g. Deployment, deploy, ingresses.networking.k8s.io)")),
 		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the resource to delete")),
 		mcp.WithString("namespace", mcp.Description("The namespace of the resource")),
+		withDryRun(),
+		withConfirmationToken(),
+		withContext(),
 	)
 }
 
@@ -154,6 +314,7 @@ func GetIngressesTool() mcp.Tool {
 		"getIngresses",
 		mcp.WithDescription("Get ingresses in the Kubernetes cluster"),
 		mcp.WithString("host", mcp.Required(), mcp.Description("The host to get ingresses from")),
+		withContext(),
 	)
 }
 
@@ -165,5 +326,7 @@ func RolloutRestartTool() mcp.Tool {
 		mcp.WithString("kind", mcp.Required(), mcp.Description("The type of resource to restart (e.g., Deployment, DaemonSet)")),
 		mcp.WithString("name", mcp.Required(), mcp.Description("The name of the resource")),
 		mcp.WithString("namespace", mcp.Required(), mcp.Description("The namespace of the resource")),
+		withDryRun(),
+		withContext(),
 	)
 }